@@ -1,14 +1,25 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
+	"github.com/a-h/templ/lsp/protocol"
 	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/git"
 	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
 	"github.com/lavigneer/evergreen-lsp/pkg/reporter"
 	"github.com/mongodb/grip"
 	"github.com/mongodb/grip/send"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
 )
 
@@ -16,11 +27,23 @@ import (
 var lintCmd = &cobra.Command{
 	Use:   "lint",
 	Short: "Lint an evergreen project",
+	Long: `Lint an evergreen project.
+
+Exits with 0 when the project passes, 1 when it fails the thresholds set by
+--fail-on and --max-warnings, and 2 when the config or a project file cannot
+be loaded or parsed.`,
+	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, _ []string) error {
+		failOn, _ := cmd.Flags().GetString("fail-on")
+		if failOn != "error" && failOn != "warning" {
+			return &exitError{code: exitConfigFailure, err: fmt.Errorf("invalid --fail-on %q, must be error or warning", failOn)}
+		}
+		maxWarnings, _ := cmd.Flags().GetInt("max-warnings")
+
 		cwd, _ := os.Getwd()
 		workspaceRoot, err := config.FindWorkspaceRoot(cwd)
 		if err != nil {
-			return err
+			return &exitError{code: exitConfigFailure, err: err}
 		}
 
 		// Have to do this to stop evergreen from logging...
@@ -28,23 +51,258 @@ var lintCmd = &cobra.Command{
 
 		cfg, err := config.NewWithDefaults(cmd.Context(), workspaceRoot)
 		if err != nil {
-			return err
+			return &exitError{code: exitConfigFailure, err: err}
+		}
+		if cmd.Flags().Changed("jobs") {
+			cfg.Lint.Jobs, _ = cmd.Flags().GetInt("jobs")
+		}
+		fix, _ := cmd.Flags().GetBool("fix")
+		fixDryRun, _ := cmd.Flags().GetBool("fix-dry-run")
+		if fixDryRun {
+			for _, p := range cfg.Projects {
+				if err := printFixDiff(cmd.OutOrStdout(), workspaceRoot, p, cfg.Lint); err != nil {
+					return &exitError{code: exitConfigFailure, err: err}
+				}
+			}
+			return nil
+		}
+		if fix {
+			for _, p := range cfg.Projects {
+				if err := fixProject(cmd.Context(), p, cfg.Lint); err != nil {
+					return &exitError{code: exitConfigFailure, err: err}
+				}
+			}
+		}
+
+		if path, _ := cmd.Flags().GetString("write-baseline"); path != "" {
+			if err := writeBaseline(cmd.OutOrStdout(), path, cfg); err != nil {
+				return &exitError{code: exitConfigFailure, err: err}
+			}
+			return nil
+		}
+		baseline, err := loadBaseline(cmd, workspaceRoot, cfg.Lint)
+		if err != nil {
+			return &exitError{code: exitConfigFailure, err: err}
+		}
+		var changes git.Changes
+		if ref, _ := cmd.Flags().GetString("changed-since"); ref != "" {
+			changes, err = git.ChangedSince(cmd.Context(), workspaceRoot, ref)
+			if err != nil {
+				return &exitError{code: exitConfigFailure, err: err}
+			}
 		}
-		rep := reporter.Default{}
-		rep.Init()
-		for _, p := range cfg.Projects {
-			lintExecutor := lint.New(p, cfg.Lint)
-			diagnostics, err := lintExecutor.Lint()
+
+		out := cmd.OutOrStdout()
+		if path, _ := cmd.Flags().GetString("output"); path != "" {
+			f, err := os.Create(path)
 			if err != nil {
-				slog.Error("Could not lint project", "project", p.Path())
+				return &exitError{code: exitConfigFailure, err: err}
+			}
+			defer f.Close()
+			out = f
+		}
+		format, _ := cmd.Flags().GetString("format")
+		linkTemplate, _ := cmd.Flags().GetString("link-template")
+		rep, err := reporter.New(format, out, reporter.Options{LinkTemplate: linkTemplate})
+		if err != nil {
+			return &exitError{code: exitConfigFailure, err: err}
+		}
+		parseFailed := false
+		errorsCount := 0
+		warningsCount := 0
+		for _, result := range lintProjects(cfg) {
+			if result.err != nil {
+				slog.Error("Could not lint project", "project", result.project.Path(), "error", result.err)
+				parseFailed = true
+			}
+			diagnostics := baseline.ApplyAll(result.project.Root(), result.diagnostics, false)
+			if changes != nil {
+				diagnostics = onlyChanged(diagnostics, changes)
 			}
-			rep.ReportDiagnostics(cmd.Context(), diagnostics)
+			for _, diags := range diagnostics {
+				for _, d := range diags {
+					switch d.Severity {
+					case protocol.DiagnosticSeverityError:
+						errorsCount++
+					case protocol.DiagnosticSeverityWarning:
+						warningsCount++
+					}
+				}
+			}
+			rep.ReportDiagnostics(cmd.Context(), result.project, diagnostics)
+		}
+		if err := rep.ReportSummary(cmd.Context()); err != nil {
+			return &exitError{code: exitConfigFailure, err: err}
+		}
+
+		switch {
+		case parseFailed:
+			return &exitError{code: exitConfigFailure, err: errors.New("some project files could not be parsed")}
+		case errorsCount > 0:
+			return &exitError{code: exitLintFailure, err: fmt.Errorf("found %d errors", errorsCount)}
+		case failOn == "warning" && warningsCount > 0:
+			return &exitError{code: exitLintFailure, err: fmt.Errorf("found %d warnings", warningsCount)}
+		case maxWarnings >= 0 && warningsCount > maxWarnings:
+			return &exitError{code: exitLintFailure, err: fmt.Errorf("found %d warnings, more than the maximum of %d", warningsCount, maxWarnings)}
 		}
-		rep.ReportSummary(cmd.Context())
 		return nil
 	},
 }
 
+type projectResult struct {
+	project     *project.Project
+	diagnostics lint.ExecutorDiagnostics
+	err         error
+}
+
+// lintProjects lints every project at once, sharing a single pool so the
+// number of documents linted at a time stays within the configured jobs.
+// Results are in the order the projects are configured.
+func lintProjects(cfg *config.Config) []projectResult {
+	pool := lint.NewPool(cfg.Lint.Jobs)
+	results := make([]projectResult, len(cfg.Projects))
+	var wg sync.WaitGroup
+	for i, p := range cfg.Projects {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			executor := lint.New(p, cfg.Lint)
+			executor.SetPool(pool)
+			diagnostics, err := executor.Lint()
+			results[i] = projectResult{project: p, diagnostics: diagnostics, err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// onlyChanged keeps the diagnostics that overlap changed lines. The whole
+// project is still linted so rules that look across files stay correct.
+func onlyChanged(diagnostics lint.ExecutorDiagnostics, changes git.Changes) lint.ExecutorDiagnostics {
+	result := make(lint.ExecutorDiagnostics, len(diagnostics))
+	for doc, diags := range diagnostics {
+		path := doc.URI.Filename()
+		// Git reports paths with symlinks resolved
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		for _, d := range diags {
+			// Diagnostic lines are 0-based while diff lines are 1-based
+			if changes.Contains(path, int(d.Range.Start.Line)+1, int(d.Range.End.Line)+1) {
+				result[doc] = append(result[doc], d)
+			}
+		}
+	}
+	return result
+}
+
+// maxFixPasses bounds how often a project is re-linted to apply fixes that
+// overlapped with ones applied in an earlier pass
+const maxFixPasses = 10
+
+// fixProject applies fixes to the project's files until no more can be applied
+func fixProject(ctx context.Context, p *project.Project, settings config.Lint) error {
+	for range maxFixPasses {
+		fixed, err := lint.New(p, settings).Fix()
+		if err != nil {
+			return err
+		}
+		if len(fixed) == 0 {
+			return nil
+		}
+		for _, f := range fixed {
+			path := f.Document.URI.Filename()
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte(f.Text), info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+		// Reload the project so the next pass sees the fixed files
+		if err := p.Init(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printFixDiff prints the changes fixProject would make as a unified diff.
+// Only a single pass is shown since the files are not changed.
+func printFixDiff(w io.Writer, workspaceRoot string, p *project.Project, settings config.Lint) error {
+	fixed, err := lint.New(p, settings).Fix()
+	if err != nil {
+		return err
+	}
+	for _, f := range fixed {
+		path := f.Document.URI.Filename()
+		if rel, err := filepath.Rel(workspaceRoot, path); err == nil {
+			path = rel
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(f.Document.Text),
+			B:        difflib.SplitLines(f.Text),
+			FromFile: "a/" + path,
+			ToFile:   "b/" + path,
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(w, diff)
+	}
+	return nil
+}
+
+// writeBaseline records every current diagnostic of the workspace
+func writeBaseline(w io.Writer, path string, cfg *config.Config) error {
+	baseline := lint.NewBaseline()
+	for _, p := range cfg.Projects {
+		diagnostics, err := lint.New(p, cfg.Lint).Lint()
+		if err != nil {
+			return err
+		}
+		baseline.Add(p.Root(), diagnostics)
+	}
+	if err := baseline.Write(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Wrote %d baseline entries to %s\n", len(baseline.Entries), path)
+	return nil
+}
+
+// loadBaseline reads the baseline passed on the command line, falling back to
+// the one in the config
+func loadBaseline(cmd *cobra.Command, workspaceRoot string, settings config.Lint) (*lint.Baseline, error) {
+	path, _ := cmd.Flags().GetString("baseline")
+	if path == "" {
+		path = settings.BaselinePath(workspaceRoot)
+	}
+	if path == "" {
+		return nil, nil
+	}
+	baseline, err := lint.LoadBaseline(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading baseline: %w", err)
+	}
+	return baseline, nil
+}
+
 func init() {
 	rootCmd.AddCommand(lintCmd)
+	lintCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &exitError{code: exitConfigFailure, err: err}
+	})
+	lintCmd.Flags().String("format", "default", "Output format, one of "+strings.Join(reporter.FormatNames(), ", "))
+	lintCmd.Flags().String("output", "", "Write the report to a file instead of stdout")
+	lintCmd.Flags().String("link-template", "", "URL with {file} and {line} placeholders to link diagnostics to in the markdown format")
+	lintCmd.Flags().Bool("fix", false, "Apply fixes to the project files before reporting")
+	lintCmd.Flags().Bool("fix-dry-run", false, "Print the fixes that would be applied as a unified diff")
+	lintCmd.Flags().String("baseline", "", "Only report diagnostics that are not in the given baseline file")
+	lintCmd.Flags().String("fail-on", "error", "Lowest severity that fails the lint, either error or warning")
+	lintCmd.Flags().Int("max-warnings", -1, "Fail when there are more warnings than this, -1 for no limit")
+	lintCmd.Flags().Int("jobs", 0, "Number of documents to lint at once, defaults to the number of CPUs")
+	lintCmd.Flags().String("changed-since", "", "Only report diagnostics on lines changed since the given git ref")
+	lintCmd.Flags().String("write-baseline", "", "Record the current diagnostics in the given baseline file")
 }
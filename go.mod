@@ -4,7 +4,9 @@ require (
 	github.com/a-h/templ v0.3.865
 	github.com/evergreen-ci/evergreen v0.0.0-20250509230847-2dc0d30a321b
 	github.com/goccy/go-yaml v1.17.1
+	github.com/metoro-io/mcp-golang v0.12.0
 	github.com/mongodb/grip v0.0.0-20250410161241-7cb1e90e324d
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/sourcegraph/jsonrpc2 v0.2.0
 	github.com/spf13/cobra v1.9.1
 )
@@ -109,7 +111,6 @@ require (
 	github.com/lufia/plan9stats v0.0.0-20231016141302-07b5767bb0ed // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/mattn/go-xmpp v0.0.1 // indirect
-	github.com/metoro-io/mcp-golang v0.12.0 // indirect
 	github.com/mholt/archiver/v3 v3.5.1 // indirect
 	github.com/mitchellh/go-homedir v1.1.0 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
@@ -125,7 +126,6 @@ require (
 	github.com/phyber/negroni-gzip v1.0.0 // indirect
 	github.com/pierrec/lz4/v4 v4.1.9 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/power-devops/perfstat v0.0.0-20221212215047-62379fc7944b // indirect
 	github.com/pquerna/cachecontrol v0.2.0 // indirect
 	github.com/robfig/cron v1.2.0 // indirect
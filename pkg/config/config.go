@@ -36,16 +36,90 @@ func (c *Config) FindProjDoc(docURI protocol.DocumentURI) (*ProjDocResult, bool)
 }
 
 type Lint struct {
-	EnforceTags     bool `yaml:"enforce_tags"`
-	NoInlineScripts bool `yaml:"no_inline_scripts"`
+	// Rules configures lint rules by their ID. Rules that are not listed run
+	// with their default severity and options.
+	Rules map[string]Rule `yaml:"rules"`
+	// CustomRules are rules defined in the config. They can be configured in
+	// Rules by their ID like built-in rules.
+	CustomRules []CustomRule `yaml:"custom_rules"`
+	// DistroCatalog is a YAML or JSON list of distros, relative to the
+	// workspace root. Distros are only checked when it is set.
+	DistroCatalog string        `yaml:"distro_catalog"`
+	Distros       DistroCatalog `yaml:"-"`
+	// Jobs is how many documents are linted at once, defaulting to the number
+	// of CPUs
+	Jobs int `yaml:"jobs"`
+	// Baseline is a file of existing diagnostics that are not reported,
+	// relative to the workspace root
+	Baseline string `yaml:"baseline"`
+	// ShowBaselined makes the language server show baselined diagnostics as
+	// hints rather than hiding them
+	ShowBaselined bool `yaml:"show_baselined"`
+
+	// EnforceTags is deprecated, `enforce_tags: false` is the same as
+	// `rules: {enforce-tags: off}`
+	EnforceTags *bool `yaml:"enforce_tags"`
+	// NoInlineScripts is deprecated, `no_inline_scripts: false` is the same as
+	// `rules: {no-inline-script: off}`
+	NoInlineScripts *bool `yaml:"no_inline_scripts"`
+}
+
+// BaselinePath returns the path of the configured baseline, if any
+func (l Lint) BaselinePath(workspacePath string) string {
+	if l.Baseline == "" || filepath.IsAbs(l.Baseline) {
+		return l.Baseline
+	}
+	return filepath.Join(workspacePath, l.Baseline)
 }
 
 const (
-	ConfigFileName         = "evergreenlsp.config.yaml"
-	DefaultEnforceTags     = true
-	DefaultNoInlineScripts = true
+	ConfigFileName        = "evergreenlsp.config.yaml"
+	DefaultMaxTimeoutSecs = 24 * 60 * 60
 )
 
+// DefaultKnownExpansions are the expansions evergreen sets for every task
+var DefaultKnownExpansions = []string{
+	"alias",
+	"author",
+	"author_email",
+	"branch_name",
+	"build_id",
+	"build_variant",
+	"created_at",
+	"distro_id",
+	"execution",
+	"github_author",
+	"github_commit",
+	"github_head_branch",
+	"github_known_hosts",
+	"github_org",
+	"github_pr_number",
+	"github_repo",
+	"is_commit_queue",
+	"is_patch",
+	"is_stepback",
+	"project",
+	"project_id",
+	"project_identifier",
+	"requester",
+	"revision",
+	"revision_order_id",
+	"task_id",
+	"task_name",
+	"trigger_branch",
+	"trigger_event_identifier",
+	"trigger_event_type",
+	"trigger_id",
+	"trigger_repo_name",
+	"trigger_repo_owner",
+	"trigger_revision",
+	"trigger_status",
+	"trigger_version",
+	"triggered_by_git_tag",
+	"version_id",
+	"workdir",
+}
+
 func NewWithDefaults(ctx context.Context, workspacePath string) (*Config, error) {
 	f, err := os.ReadFile(filepath.Join(workspacePath, ConfigFileName))
 	if err != nil && !errors.Is(err, os.ErrNotExist) {
@@ -54,16 +128,28 @@ func NewWithDefaults(ctx context.Context, workspacePath string) (*Config, error)
 
 	config := Config{
 		Projects: []*project.Project{project.New("evergreen.yml")},
-		Lint: Lint{
-			EnforceTags:     DefaultEnforceTags,
-			NoInlineScripts: DefaultNoInlineScripts,
-		},
+		Lint:     Lint{},
 	}
 	err = yaml.Unmarshal(f, &config)
 	if err != nil {
 		return nil, err
 	}
 
+	err = config.Lint.migrateDeprecated()
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.Lint.validateCustomRules()
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.Lint.loadDistroCatalog(workspacePath)
+	if err != nil {
+		return nil, fmt.Errorf("loading distro catalog: %w", err)
+	}
+
 	for _, p := range config.Projects {
 		p.SetRoot(workspacePath)
 		err := p.Init(ctx)
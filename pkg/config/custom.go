@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/goccy/go-yaml"
+)
+
+type Condition string
+
+const (
+	ConditionExists    Condition = "exists"
+	ConditionNotExists Condition = "not-exists"
+	ConditionRegex     Condition = "regex"
+	ConditionEquals    Condition = "equals"
+	ConditionOneOf     Condition = "one-of"
+)
+
+var conditions = []Condition{ConditionExists, ConditionNotExists, ConditionRegex, ConditionEquals, ConditionOneOf}
+
+// CustomRule is a rule defined in the config rather than in code. Every node
+// selected by Path, or its Field when set, must satisfy the condition, and a
+// diagnostic with the rule's message is reported for each one that does not.
+//
+//	custom_rules:
+//	  - id: task-tags
+//	    path: $.tasks[*]
+//	    field: tags
+//	    condition: exists
+//	    message: every task must have a tags list
+//	  - id: no-large-distros
+//	    path: $.buildvariants[*].run_on
+//	    condition: regex
+//	    value: -large$
+//	    negate: true
+//	    message: buildvariants may not run on large distros
+type CustomRule struct {
+	ID string `yaml:"id"`
+	// Path selects nodes with the syntax of yaml.PathString, e.g. $.tasks[*]
+	Path string `yaml:"path"`
+	// Field is a key of the selected mappings to check instead of the
+	// mappings themselves
+	Field     string    `yaml:"field"`
+	Condition Condition `yaml:"condition"`
+	// Value is the pattern of a regex condition or the expected value of an
+	// equals condition
+	Value string `yaml:"value"`
+	// Values are the allowed values of a one-of condition
+	Values []string `yaml:"values"`
+	// Negate reports nodes that satisfy the condition instead
+	Negate   bool     `yaml:"negate"`
+	Message  string   `yaml:"message"`
+	Severity Severity `yaml:"severity"`
+}
+
+// Validate checks that the rule is complete and its path and pattern compile
+func (r CustomRule) Validate() error {
+	var errs []error
+	if r.Message == "" {
+		errs = append(errs, errors.New("message is required"))
+	}
+	if _, err := yaml.PathString(r.Path); err != nil {
+		errs = append(errs, fmt.Errorf("invalid path %q: %w", r.Path, err))
+	}
+	if !slices.Contains(severities, r.Severity) {
+		errs = append(errs, fmt.Errorf("invalid severity %q, must be one of off, info, warning or error", r.Severity))
+	}
+	switch r.Condition {
+	case ConditionExists, ConditionNotExists:
+		if r.Field == "" {
+			errs = append(errs, fmt.Errorf("%s condition requires a field", r.Condition))
+		}
+	case ConditionRegex:
+		if _, err := regexp.Compile(r.Value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid regex: %w", err))
+		}
+	case ConditionOneOf:
+		if len(r.Values) == 0 {
+			errs = append(errs, errors.New("one-of condition requires values"))
+		}
+	}
+	if !slices.Contains(conditions, r.Condition) {
+		errs = append(errs, fmt.Errorf("invalid condition %q, must be one of exists, not-exists, regex, equals or one-of", r.Condition))
+	}
+	return errors.Join(errs...)
+}
+
+func (l Lint) validateCustomRules() error {
+	seen := map[string]bool{}
+	for _, r := range l.CustomRules {
+		if r.ID == "" {
+			return errors.New("custom rule is missing an id")
+		}
+		if seen[r.ID] {
+			return fmt.Errorf("custom rule %q is defined more than once", r.ID)
+		}
+		seen[r.ID] = true
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("custom rule %q: %w", r.ID, err)
+		}
+	}
+	return nil
+}
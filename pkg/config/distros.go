@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Distro is an entry of the distro catalog. Anything besides the name and
+// aliases is only shown to the user.
+type Distro struct {
+	Name    string   `yaml:"name"`
+	Aliases []string `yaml:"aliases,omitempty"`
+	// Retired distros can no longer be scheduled on
+	Retired     bool           `yaml:"retired,omitempty"`
+	Replacement string         `yaml:"replacement,omitempty"`
+	Metadata    map[string]any `yaml:"metadata,omitempty"`
+}
+
+// DistroCatalog is the list of distros a project can run on
+type DistroCatalog []Distro
+
+// LoadDistroCatalog reads a YAML or JSON list of distros
+func LoadDistroCatalog(path string) (DistroCatalog, error) {
+	f, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	catalog := DistroCatalog{}
+	if err := yaml.Unmarshal(f, &catalog); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// Find returns the distro with the given name or alias
+func (c DistroCatalog) Find(name string) (*Distro, bool) {
+	for i, d := range c {
+		if d.Name == name {
+			return &c[i], true
+		}
+	}
+	for i, d := range c {
+		for _, a := range d.Aliases {
+			if a == name {
+				return &c[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Names returns every name and alias in the catalog that can still be used
+func (c DistroCatalog) Names() []string {
+	names := []string{}
+	for _, d := range c {
+		if d.Retired {
+			continue
+		}
+		names = append(names, d.Name)
+		names = append(names, d.Aliases...)
+	}
+	return names
+}
+
+func (l *Lint) loadDistroCatalog(workspacePath string) error {
+	if l.DistroCatalog == "" {
+		return nil
+	}
+	path := l.DistroCatalog
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workspacePath, path)
+	}
+	distros, err := LoadDistroCatalog(path)
+	if err != nil {
+		return err
+	}
+	l.Distros = distros
+	return nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/goccy/go-yaml"
+)
+
+type Severity string
+
+const (
+	// SeverityDefault keeps the severity each diagnostic is reported with
+	SeverityDefault Severity = ""
+	SeverityOff     Severity = "off"
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+var severities = []Severity{SeverityDefault, SeverityOff, SeverityInfo, SeverityWarning, SeverityError}
+
+// Rule configures a single lint rule. It can be written as just a severity,
+// e.g. `enforce-tags: off`, or as a mapping with rule specific options.
+type Rule struct {
+	Severity Severity       `yaml:"severity"`
+	Options  map[string]any `yaml:"options"`
+}
+
+func (r *Rule) UnmarshalYAML(b []byte) error {
+	var severity Severity
+	if err := yaml.Unmarshal(b, &severity); err != nil {
+		rule := struct {
+			Severity Severity       `yaml:"severity"`
+			Options  map[string]any `yaml:"options"`
+		}{}
+		if err := yaml.Unmarshal(b, &rule); err != nil {
+			return err
+		}
+		*r = Rule(rule)
+	} else {
+		r.Severity = severity
+	}
+	if !slices.Contains(severities, r.Severity) {
+		return fmt.Errorf("invalid severity %q, must be one of off, info, warning or error", r.Severity)
+	}
+	return nil
+}
+
+// DecodeOptions decodes the rule's options into a struct using its yaml tags.
+// Fields without a matching option keep their current value.
+func (r Rule) DecodeOptions(v any) error {
+	if len(r.Options) == 0 {
+		return nil
+	}
+	b, err := yaml.Marshal(r.Options)
+	if err != nil {
+		return err
+	}
+	return yaml.UnmarshalWithOptions(b, v, yaml.Strict())
+}
+
+// Rule returns the settings of a rule, which are empty when it is not configured
+func (l Lint) Rule(id string) Rule {
+	return l.Rules[id]
+}
+
+// migrateDeprecated maps the boolean switches rules used to have onto their
+// rule settings. A switch that contradicts the rule's settings is an error
+// since it is unclear which one is meant.
+func (l *Lint) migrateDeprecated() error {
+	switches := []struct {
+		key     string
+		rule    string
+		enabled *bool
+	}{
+		{key: "enforce_tags", rule: "enforce-tags", enabled: l.EnforceTags},
+		{key: "no_inline_scripts", rule: "no-inline-script", enabled: l.NoInlineScripts},
+	}
+	for _, s := range switches {
+		if s.enabled == nil {
+			continue
+		}
+		slog.Warn("Deprecated lint setting, configure the rule under rules instead", "setting", s.key, "rule", s.rule)
+		if *s.enabled {
+			continue
+		}
+		rule, ok := l.Rules[s.rule]
+		if ok && rule.Severity != SeverityOff && rule.Severity != SeverityDefault {
+			return fmt.Errorf("%s is false but rules.%s sets the severity to %s, remove %s and set rules.%s to off", s.key, s.rule, rule.Severity, s.key, s.rule)
+		}
+		if l.Rules == nil {
+			l.Rules = make(map[string]Rule)
+		}
+		rule.Severity = SeverityOff
+		l.Rules[s.rule] = rule
+	}
+	return nil
+}
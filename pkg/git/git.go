@@ -0,0 +1,132 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LineRange is an inclusive range of 1-based lines
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Changes are the lines changed in each file, keyed by absolute path
+type Changes map[string][]LineRange
+
+// Contains reports whether any line of an inclusive range of 1-based lines in
+// a file changed
+func (c Changes) Contains(path string, start int, end int) bool {
+	for _, r := range c[filepath.Clean(path)] {
+		if start <= r.End && end >= r.Start {
+			return true
+		}
+	}
+	return false
+}
+
+// hunkHeader matches the new file side of a unified diff hunk header, e.g.
+// `@@ -10,2 +12,3 @@`, where the count is omitted when it is 1
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// ChangedSince returns the lines that differ between a ref and the working
+// tree of the repository containing dir, including uncommitted and untracked
+// files
+func ChangedSince(ctx context.Context, dir string, ref string) (Changes, error) {
+	top, err := run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	top = strings.TrimSpace(top)
+
+	// The prefixes are set explicitly since diff.noprefix and
+	// diff.mnemonicPrefix change them
+	diff, err := run(ctx, dir, "diff", "--no-color", "--no-ext-diff", "--unified=0", "--src-prefix=a/", "--dst-prefix=b/", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	changes, err := parseDiff(top, diff)
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := run(ctx, dir, "ls-files", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(strings.TrimSpace(untracked), "\n") {
+		if name != "" {
+			path := filepath.Join(top, filepath.FromSlash(name))
+			changes[path] = []LineRange{{Start: 1, End: math.MaxInt}}
+		}
+	}
+	return changes, nil
+}
+
+func parseDiff(top string, diff string) (Changes, error) {
+	changes := Changes{}
+	path := ""
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			path = ""
+			if name, ok := strings.CutPrefix(diffPath(line[len("+++ "):]), "b/"); ok {
+				path = filepath.Join(top, filepath.FromSlash(name))
+			}
+		case strings.HasPrefix(line, "@@ ") && path != "":
+			match := hunkHeader.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("invalid hunk header %q", line)
+			}
+			start, _ := strconv.Atoi(match[1])
+			count := 1
+			if match[2] != "" {
+				count, _ = strconv.Atoi(match[2])
+			}
+			r := LineRange{Start: start, End: start + count - 1}
+			if count == 0 {
+				// Lines were only removed after the start line, so count the
+				// lines on either side of them as changed
+				r = LineRange{Start: max(start, 1), End: start + 1}
+			}
+			changes[path] = append(changes[path], r)
+		}
+	}
+	return changes, scanner.Err()
+}
+
+// diffPath returns the path of a file header line. Git quotes paths with
+// unusual characters like a C string and ends paths containing spaces with a
+// tab.
+func diffPath(name string) string {
+	name = strings.TrimSuffix(name, "\t")
+	if strings.HasPrefix(name, `"`) {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			return unquoted
+		}
+	}
+	return name
+}
+
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,149 @@
+package git
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	top := filepath.FromSlash("/repo")
+	tests := []struct {
+		name string
+		diff string
+		want Changes
+	}{
+		{
+			name: "empty",
+			diff: "",
+			want: Changes{},
+		},
+		{
+			name: "modified file",
+			diff: `diff --git a/evergreen.yml b/evergreen.yml
+index 1111111..2222222 100644
+--- a/evergreen.yml
++++ b/evergreen.yml
+@@ -3 +3 @@ tasks:
+-  - name: a
++  - name: b
+@@ -10,0 +11,3 @@ functions:
++  setup:
++    - command: shell.exec
++      params: {}
+`,
+			want: Changes{
+				filepath.Join(top, "evergreen.yml"): {{Start: 3, End: 3}, {Start: 11, End: 13}},
+			},
+		},
+		{
+			name: "removed lines",
+			diff: `diff --git a/evergreen.yml b/evergreen.yml
+--- a/evergreen.yml
++++ b/evergreen.yml
+@@ -4,2 +3,0 @@
+-  - name: a
+-  - name: b
+@@ -1 +0,0 @@
+-tasks:
+`,
+			want: Changes{
+				filepath.Join(top, "evergreen.yml"): {{Start: 3, End: 4}, {Start: 1, End: 1}},
+			},
+		},
+		{
+			name: "quoted path",
+			diff: `diff --git "a/etc/\303\274n i.yml" "b/etc/\303\274n i.yml"` + "\n" +
+				`--- "a/etc/\303\274n i.yml"` + "\t\n" +
+				`+++ "b/etc/\303\274n i.yml"` + "\t\n" +
+				"@@ -1,0 +2 @@\n" +
+				"+tasks: []\n",
+			want: Changes{
+				filepath.Join(top, "etc", "ün i.yml"): {{Start: 2, End: 2}},
+			},
+		},
+		{
+			name: "path with spaces",
+			diff: "diff --git a/my dir/a.yml b/my dir/a.yml\n" +
+				"--- a/my dir/a.yml\t\n" +
+				"+++ b/my dir/a.yml\t\n" +
+				"@@ -5,0 +6,2 @@\n" +
+				"+a: 1\n" +
+				"+b: 2\n",
+			want: Changes{
+				filepath.Join(top, "my dir", "a.yml"): {{Start: 6, End: 7}},
+			},
+		},
+		{
+			name: "renamed file",
+			diff: `diff --git a/old.yml b/sub/new.yml
+similarity index 90%
+rename from old.yml
+rename to sub/new.yml
+index 1111111..2222222 100644
+--- a/old.yml
++++ b/sub/new.yml
+@@ -2 +2 @@
+-a: 1
++a: 2
+`,
+			want: Changes{
+				filepath.Join(top, "sub", "new.yml"): {{Start: 2, End: 2}},
+			},
+		},
+		{
+			name: "deleted file",
+			diff: `diff --git a/gone.yml b/gone.yml
+deleted file mode 100644
+--- a/gone.yml
++++ /dev/null
+@@ -1,2 +0,0 @@
+-a: 1
+-b: 2
+diff --git a/kept.yml b/kept.yml
+--- a/kept.yml
++++ b/kept.yml
+@@ -1 +1 @@
+-a: 1
++a: 2
+`,
+			want: Changes{
+				filepath.Join(top, "kept.yml"): {{Start: 1, End: 1}},
+			},
+		},
+		{
+			name: "new file",
+			diff: `diff --git a/added.yml b/added.yml
+new file mode 100644
+--- /dev/null
++++ b/added.yml
+@@ -0,0 +1,3 @@
++a: 1
++b: 2
++c: 3
+`,
+			want: Changes{
+				filepath.Join(top, "added.yml"): {{Start: 1, End: 3}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDiff(top, tt.diff)
+			if err != nil {
+				t.Fatalf("parseDiff() returned error: %v", err)
+			}
+			if !maps.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("parseDiff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDiffInvalidHunk(t *testing.T) {
+	diff := "--- a/a.yml\n+++ b/a.yml\n@@ -1 +x @@\n"
+	if _, err := parseDiff("/repo", diff); err == nil {
+		t.Error("parseDiff() returned no error for an invalid hunk header")
+	}
+}
@@ -0,0 +1,250 @@
+package lint
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+const baselineVersion = 1
+
+// Baseline records existing diagnostics so only new ones are reported.
+// Entries are matched by rule, file and a fingerprint of the offending node's
+// YAML path rather than by line, so edits elsewhere in a file do not
+// invalidate them.
+type Baseline struct {
+	Version int             `json:"version"`
+	Entries []BaselineEntry `json:"entries"`
+}
+
+type BaselineEntry struct {
+	// File is relative to the workspace root
+	File string `json:"file"`
+	Rule string `json:"rule"`
+	// Path is the YAML path the fingerprint is computed from, kept to make the
+	// baseline readable
+	Path        string `json:"path"`
+	Fingerprint string `json:"fingerprint"`
+	// Count is the number of diagnostics the entry matches
+	Count int `json:"count"`
+}
+
+type baselineKey struct {
+	file        string
+	rule        string
+	fingerprint string
+}
+
+func (e BaselineEntry) key() baselineKey {
+	return baselineKey{file: e.File, rule: e.Rule, fingerprint: e.Fingerprint}
+}
+
+// NewBaseline returns an empty baseline
+func NewBaseline() *Baseline {
+	return &Baseline{Version: baselineVersion, Entries: []BaselineEntry{}}
+}
+
+// LoadBaseline reads a baseline written by Write
+func LoadBaseline(path string) (*Baseline, error) {
+	f, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := NewBaseline()
+	if err := json.Unmarshal(f, b); err != nil {
+		return nil, err
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d", b.Version)
+	}
+	return b, nil
+}
+
+// Write saves the baseline as JSON, sorted so it diffs cleanly
+func (b *Baseline) Write(path string) error {
+	slices.SortFunc(b.Entries, func(x BaselineEntry, y BaselineEntry) int {
+		return cmp.Or(
+			cmp.Compare(x.File, y.File),
+			cmp.Compare(x.Rule, y.Rule),
+			cmp.Compare(x.Path, y.Path),
+		)
+	})
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644) //nolint:gosec
+}
+
+// Add records the diagnostics of a project in the baseline
+func (b *Baseline) Add(root string, diagnostics ExecutorDiagnostics) {
+	index := make(map[baselineKey]int, len(b.Entries))
+	for i, e := range b.Entries {
+		index[e.key()] = i
+	}
+	for doc, diags := range diagnostics {
+		file := baselineFile(root, doc)
+		for _, d := range diags {
+			path := diagnosticPath(doc, d)
+			entry := BaselineEntry{File: file, Rule: d.Source, Path: path, Fingerprint: fingerprint(path), Count: 1}
+			if i, ok := index[entry.key()]; ok {
+				b.Entries[i].Count++
+				continue
+			}
+			index[entry.key()] = len(b.Entries)
+			b.Entries = append(b.Entries, entry)
+		}
+	}
+}
+
+// Apply removes the diagnostics of a document that are in the baseline, or
+// lowers them to hints when hint is set. A nil baseline matches nothing.
+func (b *Baseline) Apply(root string, doc *project.Document, diagnostics []protocol.Diagnostic, hint bool) []protocol.Diagnostic {
+	if b == nil || len(diagnostics) == 0 {
+		return diagnostics
+	}
+	file := baselineFile(root, doc)
+	remaining := map[baselineKey]int{}
+	for _, e := range b.Entries {
+		if e.File == file {
+			remaining[e.key()] += e.Count
+		}
+	}
+	if len(remaining) == 0 {
+		return diagnostics
+	}
+
+	result := make([]protocol.Diagnostic, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		key := baselineKey{file: file, rule: d.Source, fingerprint: fingerprint(diagnosticPath(doc, d))}
+		if remaining[key] == 0 {
+			result = append(result, d)
+			continue
+		}
+		remaining[key]--
+		if hint {
+			d.Severity = protocol.DiagnosticSeverityHint
+			d.Message += " (baselined)"
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// ApplyAll applies the baseline to every document of a project
+func (b *Baseline) ApplyAll(root string, diagnostics ExecutorDiagnostics, hint bool) ExecutorDiagnostics {
+	if b == nil {
+		return diagnostics
+	}
+	result := make(ExecutorDiagnostics, len(diagnostics))
+	for doc, diags := range diagnostics {
+		result[doc] = b.Apply(root, doc, diags, hint)
+	}
+	return result
+}
+
+func baselineFile(root string, doc *project.Document) string {
+	path := doc.URI.Filename()
+	if rel, err := filepath.Rel(root, path); err == nil {
+		path = rel
+	}
+	return filepath.ToSlash(path)
+}
+
+// Fingerprint identifies a diagnostic by its rule, file and the stable path
+// of its node, so it stays the same when other parts of the file change
+func Fingerprint(root string, doc *project.Document, d protocol.Diagnostic) string {
+	return fingerprint(baselineFile(root, doc) + "\x00" + d.Source + "\x00" + diagnosticPath(doc, d))
+}
+
+func fingerprint(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(sum[:8])
+}
+
+// diagnosticPath returns the stable path of the node a diagnostic starts at
+func diagnosticPath(doc *project.Document, d protocol.Diagnostic) string {
+	if doc.AST == nil {
+		return "$"
+	}
+	// Token positions are 1-based
+	target := token.Position{Line: int(d.Range.Start.Line) + 1, Column: int(d.Range.Start.Character) + 1}
+	found := "$"
+	for _, body := range doc.AST.Docs {
+		walkStablePaths(body, "$", func(node ast.Node, path string) {
+			p := node.GetToken().Position
+			if p.Line < target.Line || (p.Line == target.Line && p.Column <= target.Column) {
+				found = path
+			}
+		})
+	}
+	return found
+}
+
+// walkStablePaths calls fn for every node in document order with a path that
+// identifies list entries by their name, function or command rather than by
+// index wherever possible, so adding or removing entries does not change the
+// paths of the others
+func walkStablePaths(node ast.Node, path string, fn func(node ast.Node, path string)) {
+	if node == nil || node.GetToken() == nil {
+		return
+	}
+	fn(node, path)
+	switch n := node.(type) {
+	case *ast.DocumentNode:
+		walkStablePaths(n.Body, path, fn)
+	case *ast.AnchorNode:
+		walkStablePaths(n.Value, path, fn)
+	case *ast.TagNode:
+		walkStablePaths(n.Value, path, fn)
+	case *ast.MappingNode:
+		for _, v := range n.Values {
+			walkStablePaths(v, path, fn)
+		}
+	case *ast.MappingValueNode:
+		childPath := path + "." + n.Key.GetToken().Value
+		walkStablePaths(n.Key, childPath, fn)
+		walkStablePaths(n.Value, childPath, fn)
+	case *ast.SequenceNode:
+		seen := map[string]int{}
+		for i, v := range n.Values {
+			id, ok := entryIdentity(v)
+			if !ok {
+				walkStablePaths(v, fmt.Sprintf("%s[%d]", path, i), fn)
+				continue
+			}
+			// Repeated identities, such as a function called twice, are told
+			// apart by their occurrence
+			occurrence := seen[id]
+			seen[id]++
+			if occurrence > 0 {
+				id = fmt.Sprintf("%s#%d", id, occurrence)
+			}
+			walkStablePaths(v, fmt.Sprintf("%s[%s]", path, id), fn)
+		}
+	}
+}
+
+// entryIdentity describes a list entry by the field that identifies it
+func entryIdentity(entry ast.Node) (string, bool) {
+	entry = UnwrapAnchor(entry)
+	if s, ok := entry.(*ast.StringNode); ok {
+		return "name=" + s.Value, true
+	}
+	for _, field := range []string{"name", "func", "command"} {
+		if v, ok := MappingField(entry, field).(*ast.StringNode); ok {
+			return field + "=" + v.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,154 @@
+package lint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/a-h/templ/lsp/uri"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// parseTestDocument parses text as a document at path without loading a project
+func parseTestDocument(t *testing.T, path string, text string) *project.Document {
+	t.Helper()
+	doc := &project.Document{TextDocumentItem: protocol.TextDocumentItem{URI: uri.File(path), Text: text}}
+	if err := doc.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// diagnosticAt builds a diagnostic of a rule starting at a 0-based position
+func diagnosticAt(rule string, line uint32, character uint32) protocol.Diagnostic {
+	start := protocol.Position{Line: line, Character: character}
+	return protocol.Diagnostic{Source: rule, Range: protocol.Range{Start: start, End: start}}
+}
+
+func TestFingerprintIgnoresInsertedLines(t *testing.T) {
+	const before = `tasks:
+  - name: compile
+    commands:
+      - func: setup
+      - command: shell.exec
+        params:
+          script: make
+`
+	tests := []struct {
+		name  string
+		after string
+		// before and after are the positions of the same node in both texts
+		beforeLine, beforeChar uint32
+		afterLine, afterChar   uint32
+	}{
+		{
+			name: "comment above",
+			after: `# build tasks
+tasks:
+  - name: compile
+    commands:
+      - func: setup
+      - command: shell.exec
+        params:
+          script: make
+`,
+			beforeLine: 4, beforeChar: 17,
+			afterLine: 5, afterChar: 17,
+		},
+		{
+			name: "task inserted above",
+			after: `tasks:
+  - name: lint
+    commands:
+      - command: shell.exec
+        params:
+          script: make lint
+  - name: compile
+    commands:
+      - func: setup
+      - command: shell.exec
+        params:
+          script: make
+`,
+			beforeLine: 6, beforeChar: 18,
+			afterLine: 11, afterChar: 18,
+		},
+		{
+			name: "command inserted above",
+			after: `tasks:
+  - name: compile
+    commands:
+      - func: fetch
+      - func: setup
+      - command: shell.exec
+        params:
+          script: make
+`,
+			beforeLine: 3, beforeChar: 14,
+			afterLine: 4, afterChar: 14,
+		},
+		{
+			name: "top level key inserted above",
+			after: `pre:
+  - command: shell.exec
+    params:
+      script: make setup
+tasks:
+  - name: compile
+    commands:
+      - func: setup
+      - command: shell.exec
+        params:
+          script: make
+`,
+			beforeLine: 1, beforeChar: 10,
+			afterLine: 5, afterChar: 10,
+		},
+	}
+	root := t.TempDir()
+	path := filepath.Join(root, "evergreen.yml")
+	oldDoc := parseTestDocument(t, path, before)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newDoc := parseTestDocument(t, path, tt.after)
+			old := Fingerprint(root, oldDoc, diagnosticAt("rule", tt.beforeLine, tt.beforeChar))
+			got := Fingerprint(root, newDoc, diagnosticAt("rule", tt.afterLine, tt.afterChar))
+			if got != old {
+				t.Errorf("fingerprint changed from %s (%s) to %s (%s)",
+					old, diagnosticPath(oldDoc, diagnosticAt("rule", tt.beforeLine, tt.beforeChar)),
+					got, diagnosticPath(newDoc, diagnosticAt("rule", tt.afterLine, tt.afterChar)))
+			}
+		})
+	}
+}
+
+func TestFingerprintDistinguishesDiagnostics(t *testing.T) {
+	const text = `tasks:
+  - name: compile
+    commands:
+      - func: setup
+      - func: setup
+  - name: test
+`
+	root := t.TempDir()
+	doc := parseTestDocument(t, filepath.Join(root, "evergreen.yml"), text)
+	other := parseTestDocument(t, filepath.Join(root, "other.yml"), text)
+	base := Fingerprint(root, doc, diagnosticAt("rule", 3, 14))
+	tests := []struct {
+		name string
+		doc  *project.Document
+		d    protocol.Diagnostic
+	}{
+		{name: "repeated function call", doc: doc, d: diagnosticAt("rule", 4, 14)},
+		{name: "other task", doc: doc, d: diagnosticAt("rule", 5, 10)},
+		{name: "other rule", doc: doc, d: diagnosticAt("other-rule", 3, 14)},
+		{name: "other file", doc: other, d: diagnosticAt("rule", 3, 14)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fingerprint(root, tt.doc, tt.d); got == base {
+				t.Errorf("fingerprint %s (%s) matches the first call of setup", got, diagnosticPath(tt.doc, tt.d))
+			}
+		})
+	}
+}
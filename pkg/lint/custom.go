@@ -0,0 +1,188 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/util"
+)
+
+// CustomRuleLinter runs a rule defined in the config
+type CustomRuleLinter struct {
+	executor *Executor
+	rule     config.CustomRule
+	selector *regexp.Regexp
+	pattern  *regexp.Regexp
+}
+
+func NewCustomRuleLinter(rule config.CustomRule) (*CustomRuleLinter, error) {
+	selector, err := compileSelector(rule.Path)
+	if err != nil {
+		return nil, err
+	}
+	l := &CustomRuleLinter{rule: rule, selector: selector}
+	if rule.Condition == config.ConditionRegex {
+		l.pattern, err = regexp.Compile(rule.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *CustomRuleLinter) Register(executor *Executor) {
+	l.executor = executor
+}
+
+func (l *CustomRuleLinter) Enabled(_ config.Lint) bool {
+	return true
+}
+
+func (l *CustomRuleLinter) Check(node ast.Node) []protocol.Diagnostic {
+	// Keys share the path of their values, so only look at the values of
+	// mappings and the entries of lists to check each node once
+	targets := []ast.Node{}
+	switch n := node.(type) {
+	case *ast.MappingValueNode:
+		targets = append(targets, n.Value)
+	case *ast.SequenceNode:
+		targets = append(targets, n.Values...)
+	case *ast.MappingNode:
+		if n.GetPath() == "$" {
+			targets = append(targets, n)
+		}
+	}
+
+	diagnostics := []protocol.Diagnostic{}
+	for _, target := range targets {
+		if target == nil || !l.selector.MatchString(target.GetPath()) {
+			continue
+		}
+		for _, violation := range l.violations(target) {
+			diagnostics = append(diagnostics, protocol.Diagnostic{
+				Source:   l.rule.ID,
+				Message:  l.rule.Message,
+				Severity: l.severity(),
+				Range:    util.RangeFromNode(violation, nil),
+			})
+		}
+	}
+	return diagnostics
+}
+
+// violations returns the nodes that do not satisfy the rule's condition
+func (l *CustomRuleLinter) violations(target ast.Node) []ast.Node {
+	value := UnwrapAnchor(target)
+	reportAt := target
+	if l.rule.Field != "" {
+		value = nil
+		if entry := mappingEntry(target, l.rule.Field); entry != nil {
+			value = UnwrapAnchor(entry.Value)
+			reportAt = entry
+		} else if name := EntryField(target, "name"); name != nil {
+			reportAt = name
+		}
+	}
+	// Mappings without a name are reported at their first key rather than
+	// the position the parser gives them
+	if m, ok := UnwrapAnchor(reportAt).(*ast.MappingNode); ok && len(m.Values) > 0 {
+		reportAt = m.Values[0]
+	}
+
+	switch l.rule.Condition {
+	case config.ConditionExists, config.ConditionNotExists:
+		exists := value != nil
+		satisfied := exists == (l.rule.Condition == config.ConditionExists)
+		if satisfied != l.rule.Negate {
+			return nil
+		}
+		return []ast.Node{reportAt}
+	}
+
+	if value == nil {
+		return nil
+	}
+	violations := []ast.Node{}
+	// Lists are checked entry by entry since evergreen accepts both a single
+	// value and a list for most fields
+	for _, entry := range SequenceEntries(value) {
+		if _, ok := entry.(ast.ScalarNode); !ok {
+			continue
+		}
+		if l.matches(entry.GetToken().Value) == l.rule.Negate {
+			violations = append(violations, entry)
+		}
+	}
+	return violations
+}
+
+func (l *CustomRuleLinter) matches(value string) bool {
+	switch l.rule.Condition {
+	case config.ConditionRegex:
+		return l.pattern.MatchString(value)
+	case config.ConditionEquals:
+		return value == l.rule.Value
+	case config.ConditionOneOf:
+		return slices.Contains(l.rule.Values, value)
+	}
+	return true
+}
+
+func (l *CustomRuleLinter) severity() protocol.DiagnosticSeverity {
+	if s, ok := severities[l.rule.Severity]; ok {
+		return s
+	}
+	return protocol.DiagnosticSeverityWarning
+}
+
+// selectorSegment matches the parts of a yaml.PathString selector that can
+// be compared against node paths: recursive keys, quoted and plain keys, and
+// list indexes including the * wildcard
+var selectorSegment = regexp.MustCompile(`^(?:\.\.([^.\[\]']+)|\.'([^']*)'|\.([^.\[\]']+)|\[(\d+|\*)\])`)
+
+// compileSelector converts a selector into a regular expression matching the
+// paths of the nodes it selects
+func compileSelector(selector string) (*regexp.Regexp, error) {
+	rest, ok := strings.CutPrefix(selector, "$")
+	if !ok {
+		return nil, fmt.Errorf("path %q must start with $", selector)
+	}
+	pattern := strings.Builder{}
+	pattern.WriteString(`^\$`)
+	for rest != "" {
+		match := selectorSegment.FindStringSubmatch(rest)
+		if match == nil {
+			return nil, fmt.Errorf("unsupported path %q at %q", selector, rest)
+		}
+		rest = rest[len(match[0]):]
+		switch {
+		case match[1] != "":
+			pattern.WriteString(`(?:\.[^.\[]+|\.'[^']*'|\[\d+\])*`)
+			pattern.WriteString(keyPattern(match[1]))
+		case match[2] != "":
+			pattern.WriteString(keyPattern(match[2]))
+		case match[3] != "":
+			pattern.WriteString(keyPattern(match[3]))
+		case match[4] == "*":
+			pattern.WriteString(`\[\d+\]`)
+		default:
+			pattern.WriteString(`\[` + match[4] + `\]`)
+		}
+	}
+	pattern.WriteString(`$`)
+	return regexp.Compile(pattern.String())
+}
+
+// keyPattern matches a key in a node path. The parser quotes keys containing
+// characters that have a meaning in paths and leaves every other key as is.
+func keyPattern(key string) string {
+	if strings.ContainsAny(key, "$*.[]") {
+		return `\.'` + regexp.QuoteMeta(key) + `'`
+	}
+	return `\.` + regexp.QuoteMeta(key)
+}
@@ -0,0 +1,98 @@
+package lint
+
+import (
+	"testing"
+)
+
+func TestCompileSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		match    []string
+		noMatch  []string
+	}{
+		{
+			selector: "$",
+			match:    []string{"$"},
+			noMatch:  []string{"$.tasks"},
+		},
+		{
+			selector: "$.tasks",
+			match:    []string{"$.tasks"},
+			noMatch:  []string{"$.tasks[0]", "$.task_groups", "$.pre.tasks"},
+		},
+		{
+			selector: "$.tasks[*].name",
+			match:    []string{"$.tasks[0].name", "$.tasks[12].name"},
+			noMatch:  []string{"$.tasks.name", "$.tasks[0].commands[0].name", "$.tasks[0].names"},
+		},
+		{
+			selector: "$.tasks[1]",
+			match:    []string{"$.tasks[1]"},
+			noMatch:  []string{"$.tasks[0]", "$.tasks[10]"},
+		},
+		{
+			selector: "$..command",
+			match:    []string{"$.command", "$.tasks[0].commands[1].command", "$.functions.setup[0].command", "$.functions.'a.b'[0].command"},
+			noMatch:  []string{"$.tasks[0].commands", "$.tasks[0].command.params"},
+		},
+		{
+			selector: "$.buildvariants[*]..run_on",
+			match:    []string{"$.buildvariants[0].run_on", "$.buildvariants[2].tasks[0].run_on"},
+			noMatch:  []string{"$.run_on", "$.tasks[0].run_on"},
+		},
+		{
+			selector: "$.functions.'a.b'",
+			match:    []string{"$.functions.'a.b'"},
+			noMatch:  []string{"$.functions.a.b", "$.functions.'aXb'"},
+		},
+		{
+			selector: "$.functions.setup",
+			match:    []string{"$.functions.setup"},
+			noMatch:  []string{"$.functions.setup2", "$.functions.'setup'"},
+		},
+		{
+			selector: "$.expansions.a+b",
+			match:    []string{"$.expansions.a+b"},
+			noMatch:  []string{"$.expansions.aab"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			re, err := compileSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("compileSelector(%q) returned error: %v", tt.selector, err)
+			}
+			for _, path := range tt.match {
+				if !re.MatchString(path) {
+					t.Errorf("selector %q does not match %q", tt.selector, path)
+				}
+			}
+			for _, path := range tt.noMatch {
+				if re.MatchString(path) {
+					t.Errorf("selector %q matches %q", tt.selector, path)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileSelectorErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"tasks",
+		".tasks",
+		"$tasks",
+		"$.tasks[",
+		"$.tasks[-1]",
+		"$.tasks[a]",
+		"$...name",
+		"$.'unterminated",
+	}
+	for _, selector := range tests {
+		t.Run(selector, func(t *testing.T) {
+			if _, err := compileSelector(selector); err == nil {
+				t.Errorf("compileSelector(%q) returned no error", selector)
+			}
+		})
+	}
+}
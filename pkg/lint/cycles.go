@@ -0,0 +1,207 @@
+package lint
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+	"github.com/lavigneer/evergreen-lsp/pkg/util"
+)
+
+// DependencyCycleLinter reports cycles in the depends_on graph. The graph is
+// built from the translated project so dependencies from tag selectors and
+// build variant overrides are included.
+type DependencyCycleLinter struct {
+	executor *Executor
+}
+
+func (l *DependencyCycleLinter) Register(executor *Executor) {
+	l.executor = executor
+}
+
+func (l *DependencyCycleLinter) Enabled(_ config.Lint) bool {
+	return true
+}
+
+// dependencySource is the depends_on node that introduces an edge in the graph
+type dependencySource struct {
+	doc  *project.Document
+	node ast.Node
+}
+
+type dependencyCycle struct {
+	path     []string
+	variants []string
+	edges    []dependencySource
+}
+
+func (l *DependencyCycleLinter) CheckProject(project *ProjectContext) ExecutorDiagnostics {
+	diagnostics := make(ExecutorDiagnostics)
+	graph := project.Project.DependencyGraph()
+	cycles := []*dependencyCycle{}
+	cyclesByKey := make(map[string]*dependencyCycle)
+	for _, scc := range graph.Cycles() {
+		path := cyclePath(&graph, scc)
+		if len(path) == 0 {
+			continue
+		}
+
+		sameVariant := !slices.ContainsFunc(path, func(t task.TaskNode) bool {
+			return t.Variant != path[0].Variant
+		})
+		names := make([]string, 0, len(path))
+		for _, t := range path {
+			if sameVariant {
+				names = append(names, t.Name)
+			} else {
+				names = append(names, t.String())
+			}
+		}
+		edges := make([]dependencySource, 0, len(path)-1)
+		for i := range len(path) - 1 {
+			edges = append(edges, findDependencySource(project, path[i], path[i+1]))
+		}
+
+		// The same task level cycle shows up once per variant, so merge those
+		// into a single report
+		key := strings.Join(names, "\x00")
+		for _, e := range edges {
+			key += fmt.Sprintf("\x00%p", e.node)
+		}
+		c, ok := cyclesByKey[key]
+		if !ok {
+			c = &dependencyCycle{path: names, edges: edges}
+			cyclesByKey[key] = c
+			cycles = append(cycles, c)
+		}
+		if sameVariant {
+			c.variants = append(c.variants, path[0].Variant)
+		}
+	}
+
+	for _, c := range cycles {
+		slices.Sort(c.variants)
+		message := fmt.Sprintf("tasks form a dependency cycle: %s", strings.Join(c.path, " -> "))
+		if len(c.variants) > 0 {
+			message += fmt.Sprintf(" (variants: %s)", strings.Join(c.variants, ", "))
+		}
+		related := make([]protocol.DiagnosticRelatedInformation, 0, len(c.edges))
+		for i, e := range c.edges {
+			if e.node == nil {
+				continue
+			}
+			related = append(related, protocol.DiagnosticRelatedInformation{
+				Location: e.doc.LocationFromNode(e.node),
+				Message:  fmt.Sprintf("%s depends on %s", c.path[i], c.path[i+1]),
+			})
+		}
+		reported := make(map[ast.Node]struct{})
+		for _, e := range c.edges {
+			if e.node == nil {
+				continue
+			}
+			if _, ok := reported[e.node]; ok {
+				continue
+			}
+			reported[e.node] = struct{}{}
+			diagnostics[e.doc] = append(diagnostics[e.doc], protocol.Diagnostic{
+				Severity:           protocol.DiagnosticSeverityError,
+				Source:             "no-dependency-cycle",
+				Message:            message,
+				Range:              util.RangeFromNode(e.node, nil),
+				RelatedInformation: related,
+			})
+		}
+	}
+	return diagnostics
+}
+
+// cyclePath orders a strongly connected component into a closed path that
+// starts and ends at the same task
+func cyclePath(graph *task.DependencyGraph, scc []task.TaskNode) []task.TaskNode {
+	if len(scc) == 0 {
+		return nil
+	}
+	// Evergreen finds cycles by iterating over maps, so always start from the
+	// same task to report the same path on every run
+	scc = slices.SortedFunc(slices.Values(scc), func(a task.TaskNode, b task.TaskNode) int {
+		return cmp.Compare(a.String(), b.String())
+	})
+	start := scc[0]
+	visited := map[task.TaskNode]bool{start: true}
+	var walk func(path []task.TaskNode) []task.TaskNode
+	walk = func(path []task.TaskNode) []task.TaskNode {
+		current := path[len(path)-1]
+		if len(path) > 1 && graph.GetDependencyEdge(current, start) != nil {
+			return append(path, start)
+		}
+		for _, next := range scc {
+			if visited[next] || graph.GetDependencyEdge(current, next) == nil {
+				continue
+			}
+			visited[next] = true
+			if found := walk(append(path, next)); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	if graph.GetDependencyEdge(start, start) != nil {
+		return []task.TaskNode{start, start}
+	}
+	return walk([]task.TaskNode{start})
+}
+
+// findDependencySource locates the depends_on entry responsible for an edge,
+// preferring the build variant override since it takes precedence over the
+// task definition
+func findDependencySource(project *ProjectContext, from task.TaskNode, to task.TaskNode) dependencySource {
+	data := project.Project
+	unitName := from.Name
+	if bv := data.FindBuildVariant(from.Variant); bv != nil {
+		unit, err := bv.Get(from.Name)
+		if err != nil {
+			if tg := data.FindTaskGroupForTask(from.Variant, from.Name); tg != nil {
+				unitName = tg.Name
+				unit, err = bv.Get(tg.Name)
+			}
+		}
+		if err == nil && len(unit.DependsOn) > 0 {
+			for _, def := range project.Locate(bv) {
+				entry := findNamedEntry(MappingField(def.Entry, "tasks"), unitName)
+				if n := dependsOnNode(entry, to); n != nil {
+					return dependencySource{doc: def.Document, node: n}
+				}
+			}
+		}
+	}
+	if pt := data.FindProjectTask(from.Name); pt != nil {
+		for _, def := range project.Locate(pt) {
+			if n := dependsOnNode(def.Entry, to); n != nil {
+				return dependencySource{doc: def.Document, node: n}
+			}
+		}
+	}
+	return dependencySource{}
+}
+
+// dependsOnNode returns the depends_on entry naming the target task, falling
+// back to the whole depends_on field when it is only matched by a selector
+func dependsOnNode(node ast.Node, to task.TaskNode) ast.Node {
+	dependsOn := mappingEntry(node, "depends_on")
+	if dependsOn == nil {
+		return nil
+	}
+	for _, entry := range SequenceEntries(dependsOn.Value) {
+		if n := EntryField(entry, "name"); n != nil && n.GetToken().Value == to.Name {
+			return n
+		}
+	}
+	return dependsOn
+}
@@ -0,0 +1,144 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// loadTestProject writes a project config to a temporary directory and loads
+// it the way the language server and CLI do
+func loadTestProject(t *testing.T, config string) *project.Project {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "evergreen.yml"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p := project.New("evergreen.yml")
+	p.SetRoot(dir)
+	if err := p.Init(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDependencyCycleLinter(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		// want holds the 1-based line and message of every diagnostic
+		want []string
+	}{
+		{
+			name: "no cycle",
+			config: `tasks:
+  - name: a
+  - name: b
+    depends_on: [{name: a}]
+buildvariants:
+  - name: v
+    run_on: [d]
+    tasks: [a, b]
+`,
+			want: []string{},
+		},
+		{
+			name: "two tasks in one variant",
+			config: `tasks:
+  - name: a
+    depends_on: [{name: b}]
+  - name: b
+    depends_on: [{name: a}]
+buildvariants:
+  - name: v
+    run_on: [d]
+    tasks: [a, b]
+`,
+			want: []string{
+				"3: tasks form a dependency cycle: a -> b -> a (variants: v)",
+				"5: tasks form a dependency cycle: a -> b -> a (variants: v)",
+			},
+		},
+		{
+			name: "same cycle in two variants is reported once",
+			config: `tasks:
+  - name: a
+    depends_on: [{name: b}]
+  - name: b
+    depends_on: [{name: a}]
+buildvariants:
+  - name: v
+    run_on: [d]
+    tasks: [a, b]
+  - name: w
+    run_on: [d]
+    tasks: [a, b]
+`,
+			want: []string{
+				"3: tasks form a dependency cycle: a -> b -> a (variants: v, w)",
+				"5: tasks form a dependency cycle: a -> b -> a (variants: v, w)",
+			},
+		},
+		{
+			name: "across variants",
+			config: `tasks:
+  - name: a
+    depends_on: [{name: b, variant: w}]
+  - name: b
+    depends_on: [{name: a, variant: v}]
+buildvariants:
+  - name: v
+    run_on: [d]
+    tasks: [a]
+  - name: w
+    run_on: [d]
+    tasks: [b]
+`,
+			want: []string{
+				"3: tasks form a dependency cycle: v/a -> w/b -> v/a",
+				"5: tasks form a dependency cycle: v/a -> w/b -> v/a",
+			},
+		},
+		{
+			name: "variant override",
+			config: `tasks:
+  - name: a
+  - name: b
+    depends_on: [{name: a}]
+buildvariants:
+  - name: v
+    run_on: [d]
+    tasks:
+      - name: a
+        depends_on: [{name: b}]
+      - name: b
+`,
+			want: []string{
+				"4: tasks form a dependency cycle: a -> b -> a (variants: v)",
+				"10: tasks form a dependency cycle: a -> b -> a (variants: v)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := loadTestProject(t, tt.config)
+			linter := &DependencyCycleLinter{}
+			got := []string{}
+			for _, diags := range linter.CheckProject(newProjectContext(p)) {
+				for _, d := range diags {
+					got = append(got, fmt.Sprintf("%d: %s", d.Range.Start.Line+1, d.Message))
+				}
+			}
+			// Diagnostics are grouped by document in a map so their order is not fixed
+			slices.Sort(got)
+			slices.Sort(tt.want)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got diagnostics %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
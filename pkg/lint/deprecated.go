@@ -2,22 +2,36 @@ package lint
 
 import (
 	"fmt"
+	"math"
 	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/a-h/templ/lsp/protocol"
 	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
 	"github.com/lavigneer/evergreen-lsp/pkg/config"
 	"github.com/lavigneer/evergreen-lsp/pkg/util"
 )
 
 var deprecatedCommands = []string{"shell.exec"}
 
+// shellExecOnlyParams are shell.exec params that subprocess.exec cannot
+// express when running the script with `sh -c`, so commands setting them are
+// not rewritten automatically. Params both commands share are kept as is.
+var shellExecOnlyParams = []string{"shell", "exec_as_string"}
+
 type DeprecatedLinter struct {
 	executor *Executor
+	options  struct {
+		// Commands are deprecated in addition to deprecatedCommands
+		Commands []string `yaml:"commands"`
+	}
 }
 
 func (l *DeprecatedLinter) Register(executor *Executor) {
 	l.executor = executor
+	executor.ruleOptions("deprecated-command", &l.options)
 }
 
 func (l *DeprecatedLinter) Enabled(_ config.Lint) bool {
@@ -26,19 +40,186 @@ func (l *DeprecatedLinter) Enabled(_ config.Lint) bool {
 
 func (l *DeprecatedLinter) Check(node ast.Node) []protocol.Diagnostic {
 	diagnostics := []protocol.Diagnostic{}
-	if n, ok := node.(*ast.MappingValueNode); ok {
-		if n.Key.GetToken().Value == "command" {
-			nodeStr := n.Value.GetToken().Value
-			deprecated := slices.Contains(deprecatedCommands, nodeStr)
+	if n, ok := node.(*ast.MappingNode); ok {
+		if commandNode := MappingField(n, "command"); commandNode != nil {
+			nodeStr := commandNode.GetToken().Value
+			deprecated := slices.Contains(deprecatedCommands, nodeStr) || slices.Contains(l.options.Commands, nodeStr)
 			if deprecated {
-				diagnostics = append(diagnostics, protocol.Diagnostic{
+				d := protocol.Diagnostic{
 					Source:   "deprecated-command",
 					Message:  fmt.Sprintf("command %q is deprecated", nodeStr),
 					Severity: protocol.DiagnosticSeverityWarning,
-					Range:    util.RangeFromNode(n.Value, nil),
-				})
+					Range:    util.RangeFromNode(commandNode, nil),
+				}
+				if nodeStr == "shell.exec" {
+					if param, ok := shellExecOnlyParam(n); ok {
+						d.Message += fmt.Sprintf(", replace it with subprocess.exec by hand since %s does not carry over as is", param)
+					} else if fix, ok := shellExecFix(n); ok {
+						d.Data = fix
+					}
+				}
+				diagnostics = append(diagnostics, d)
 			}
 		}
 	}
 	return diagnostics
 }
+
+// shellExecOnlyParam returns the first param of a shell.exec command that
+// prevents rewriting it into subprocess.exec
+func shellExecOnlyParam(command *ast.MappingNode) (string, bool) {
+	params, ok := MappingField(command, "params").(*ast.MappingNode)
+	if !ok {
+		return "", false
+	}
+	for _, v := range params.Values {
+		key := v.Key.GetToken().Value
+		for _, p := range shellExecOnlyParams {
+			// Params are decoded without regard to case
+			if strings.EqualFold(key, p) {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+// shellExecFix rewrites a shell.exec command into the equivalent
+// subprocess.exec, which runs the script with sh. Commands using params
+// subprocess.exec does not support are left alone.
+func shellExecFix(command *ast.MappingNode) (*Fix, bool) {
+	params, ok := MappingField(command, "params").(*ast.MappingNode)
+	if !ok || params.IsFlowStyle {
+		return nil, false
+	}
+	rename, ok := replaceNodeEdit(MappingField(command, "command"), "subprocess.exec")
+	if !ok {
+		return nil, false
+	}
+	edits := []protocol.TextEdit{rename}
+	supported := commandSchemas()["subprocess.exec"].params
+	var script *ast.MappingValueNode
+	for _, v := range params.Values {
+		key := v.Key.GetToken().Value
+		switch {
+		case v.Key.Type() == ast.MergeKeyType:
+			return nil, false
+		case key == "script":
+			script = v
+		default:
+			if _, ok := supported[key]; !ok {
+				return nil, false
+			}
+		}
+	}
+	if script == nil {
+		return nil, false
+	}
+
+	keyToken := script.Key.GetToken()
+	indent := strings.Repeat(" ", keyToken.Position.Column-1)
+	arg, ok := scriptArg(script.Value, indent+"    ")
+	if !ok {
+		return nil, false
+	}
+	end, ok := valueEnd(script)
+	if !ok {
+		return nil, false
+	}
+	edits = append(edits, protocol.TextEdit{
+		Range: protocol.Range{
+			Start: protocol.Position{
+				Line:      uint32(keyToken.Position.Line - 1),   //nolint:gosec
+				Character: uint32(keyToken.Position.Column - 1), //nolint:gosec
+			},
+			End: end,
+		},
+		NewText: fmt.Sprintf("binary: sh\n%sargs:\n%s  - -c\n%s  - %s", indent, indent, indent, arg),
+	})
+	return &Fix{Title: "Replace with subprocess.exec", Edits: edits}, true
+}
+
+// scriptArg formats a script as a list entry, using a literal block indented
+// by the given prefix when it spans multiple lines. The result ends with a
+// newline.
+func scriptArg(node ast.Node, indent string) (string, bool) {
+	var script string
+	switch n := node.(type) {
+	case *ast.LiteralNode:
+		script = n.Value.Value
+	case *ast.StringNode:
+		if !strings.Contains(n.Value, "\n") {
+			return strconv.Quote(n.Value) + "\n", true
+		}
+		script = n.Value
+	default:
+		return "", false
+	}
+	// Leading whitespace would need an explicit indentation indicator
+	if strings.HasPrefix(script, " ") || strings.HasPrefix(script, "\n") {
+		return "", false
+	}
+
+	body := strings.TrimRight(script, "\n")
+	trailing := len(script) - len(body)
+	header := "|"
+	switch {
+	case trailing == 0:
+		header = "|-"
+	case trailing > 1:
+		header = "|+"
+	}
+	var b strings.Builder
+	b.WriteString(header + "\n")
+	for _, line := range strings.Split(body, "\n") {
+		if line != "" {
+			b.WriteString(indent + line)
+		}
+		b.WriteString("\n")
+	}
+	for range trailing - 1 {
+		b.WriteString("\n")
+	}
+	return b.String(), true
+}
+
+// valueEnd returns the start of the line following a block mapping entry's
+// value. Single line scalars and literal blocks end on a known line, anything
+// else is assumed to end where the next token begins. It fails when something
+// else follows the value on its last line.
+func valueEnd(v *ast.MappingValueNode) (protocol.Position, bool) {
+	last := v.Value.GetToken()
+	switch n := v.Value.(type) {
+	case *ast.StringNode:
+		if !strings.Contains(strings.TrimSpace(last.Origin), "\n") {
+			return lineAfter(last, last.Position.Line)
+		}
+	case *ast.LiteralNode:
+		last = n.Value.GetToken()
+		if strings.HasPrefix(n.Start.Value, "|") {
+			body := strings.TrimRight(n.Value.Value, "\n")
+			lines := strings.Count(body, "\n") + 1
+			if strings.Contains(n.Start.Value, "+") {
+				lines += len(n.Value.Value) - len(body) - 1
+			}
+			return lineAfter(last, last.Position.Line+lines-1)
+		}
+	}
+	if last.Next == nil {
+		return protocol.Position{Line: math.MaxUint32}, true
+	}
+	next := last.Next.Position.Line
+	if next <= v.Key.GetToken().Position.Line || next <= last.Position.Line {
+		return protocol.Position{}, false
+	}
+	return protocol.Position{Line: uint32(next - 1)}, true //nolint:gosec
+}
+
+// lineAfter returns the start of the line after the 1-based line a value ends
+// on, as long as no other token follows it on that line
+func lineAfter(last *token.Token, line int) (protocol.Position, bool) {
+	if last.Next != nil && last.Next.Position.Line <= line {
+		return protocol.Position{}, false
+	}
+	return protocol.Position{Line: uint32(line)}, true //nolint:gosec
+}
@@ -0,0 +1,78 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/util"
+)
+
+// distroPath matches run_on and distros values of build variants, their task
+// entries, tasks and task groups
+var distroPath = regexp.MustCompile(`^\$\.(buildvariants|tasks|task_groups)\[\d+\](\.tasks\[\d+\])?\.(run_on|distros)(\[\d+\])?$`)
+
+// IsDistroPath reports whether the node at a path names a distro
+func IsDistroPath(path string) bool {
+	return distroPath.MatchString(path)
+}
+
+type UnknownDistroLinter struct {
+	executor *Executor
+}
+
+func (l *UnknownDistroLinter) Register(executor *Executor) {
+	l.executor = executor
+}
+
+// Enabled only runs the linter when there is a distro catalog to check against
+func (l *UnknownDistroLinter) Enabled(settings config.Lint) bool {
+	return len(settings.Distros) > 0
+}
+
+func (l *UnknownDistroLinter) Check(node ast.Node) []protocol.Diagnostic {
+	diagnostics := []protocol.Diagnostic{}
+	n, ok := node.(*ast.MappingValueNode)
+	if !ok || !IsDistroPath(n.GetPath()) {
+		return diagnostics
+	}
+	for _, entry := range SequenceEntries(n.Value) {
+		if s, ok := entry.(*ast.StringNode); ok {
+			diagnostics = append(diagnostics, l.checkDistro(s)...)
+		}
+	}
+	return diagnostics
+}
+
+func (l *UnknownDistroLinter) checkDistro(n *ast.StringNode) []protocol.Diagnostic {
+	diagnostics := []protocol.Diagnostic{}
+	catalog := l.executor.settings.Distros
+	distro, ok := catalog.Find(n.Value)
+	switch {
+	case !ok:
+		message := fmt.Sprintf("distro %q is not in the distro catalog", n.Value)
+		if suggestion, ok := util.ClosestMatch(n.Value, catalog.Names()); ok {
+			message += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Severity: protocol.DiagnosticSeverityError,
+			Source:   "no-unknown-distro",
+			Message:  message,
+			Range:    util.RangeFromNode(n, nil),
+		})
+	case distro.Retired:
+		message := fmt.Sprintf("distro %q is retired", n.Value)
+		if distro.Replacement != "" {
+			message += fmt.Sprintf(", use %q instead", distro.Replacement)
+		}
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Severity: protocol.DiagnosticSeverityWarning,
+			Source:   "no-unknown-distro",
+			Message:  message,
+			Range:    util.RangeFromNode(n, nil),
+		})
+	}
+	return diagnostics
+}
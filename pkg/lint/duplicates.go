@@ -0,0 +1,136 @@
+package lint
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// DuplicateDefinitionLinter reports tasks, task groups, functions and build
+// variants that are defined more than once across the project's documents
+type DuplicateDefinitionLinter struct{}
+
+func (l *DuplicateDefinitionLinter) Register(_ *Executor) {}
+
+func (l *DuplicateDefinitionLinter) Enabled(_ config.Lint) bool {
+	return true
+}
+
+func (l *DuplicateDefinitionLinter) CheckProject(project *ProjectContext) ExecutorDiagnostics {
+	diagnostics := make(ExecutorDiagnostics)
+	for _, kind := range []EntityKind{EntityTask, EntityTaskGroup, EntityBuildVariant, EntityFunction} {
+		for _, name := range project.Names(kind) {
+			defs := project.Definitions(kind, name)
+			if len(defs) < 2 {
+				continue
+			}
+			if kind == EntityBuildVariant {
+				l.reportBuildVariant(diagnostics, name, defs)
+			} else {
+				l.reportRejected(diagnostics, string(kind), name, defs)
+			}
+		}
+	}
+	return diagnostics
+}
+
+// reportRejected reports entities evergreen refuses to merge, which fails the
+// whole project rather than picking one of the definitions
+func (l *DuplicateDefinitionLinter) reportRejected(diagnostics ExecutorDiagnostics, kind string, name string, defs []Definition) {
+	for i, def := range defs {
+		l.add(diagnostics, def, defs, i, protocol.DiagnosticSeverityError,
+			fmt.Sprintf("%s %q is defined %d times, evergreen rejects projects that declare a %s more than once", kind, name, len(defs), kind))
+	}
+}
+
+// reportBuildVariant follows evergreen's build variant merge rules. The first
+// definition is kept and later definitions from other files only contribute
+// their tasks and display tasks, which is only allowed when one of the two
+// definitions does nothing but list tasks.
+func (l *DuplicateDefinitionLinter) reportBuildVariant(diagnostics ExecutorDiagnostics, name string, defs []Definition) {
+	first := defs[0]
+	rejected := false
+	for i, def := range defs[1:] {
+		i++
+		switch {
+		case def.Document == first.Document || slices.ContainsFunc(defs[1:i], func(d Definition) bool { return d.Document == def.Document }):
+			rejected = true
+			l.add(diagnostics, def, defs, i, protocol.DiagnosticSeverityError,
+				fmt.Sprintf("build variant %q is defined more than once in the same file, evergreen rejects duplicate build variants", name))
+		case !onlyListsTasks(first.Entry) && !onlyListsTasks(def.Entry):
+			rejected = true
+			l.add(diagnostics, def, defs, i, protocol.DiagnosticSeverityError,
+				fmt.Sprintf("build variant %q is already defined in %s, evergreen rejects the merge because both definitions set fields other than tasks and display_tasks", name, displayPath(first.Document)))
+		case !onlyListsTasks(def.Entry):
+			l.add(diagnostics, def, defs, i, protocol.DiagnosticSeverityWarning,
+				fmt.Sprintf("build variant %q is first defined in %s, evergreen only merges the tasks and display_tasks of this definition and ignores its other fields", name, displayPath(first.Document)))
+		default:
+			l.add(diagnostics, def, defs, i, protocol.DiagnosticSeverityInformation,
+				fmt.Sprintf("build variant %q is first defined in %s, evergreen appends the tasks and display_tasks of this definition to it", name, displayPath(first.Document)))
+		}
+	}
+	if rejected {
+		l.add(diagnostics, first, defs, 0, protocol.DiagnosticSeverityError,
+			fmt.Sprintf("build variant %q is defined %d times and evergreen cannot merge the definitions", name, len(defs)))
+	} else {
+		l.add(diagnostics, first, defs, 0, protocol.DiagnosticSeverityInformation,
+			fmt.Sprintf("build variant %q is defined %d times, evergreen keeps this definition and merges the tasks of the others into it", name, len(defs)))
+	}
+}
+
+// add reports a definition with related information pointing at all the others
+func (l *DuplicateDefinitionLinter) add(diagnostics ExecutorDiagnostics, def Definition, defs []Definition, index int, severity protocol.DiagnosticSeverity, message string) {
+	related := make([]protocol.DiagnosticRelatedInformation, 0, len(defs)-1)
+	for i, other := range defs {
+		if i == index {
+			continue
+		}
+		related = append(related, protocol.DiagnosticRelatedInformation{
+			Location: other.Location(),
+			Message:  "also defined here",
+		})
+	}
+	diagnostics[def.Document] = append(diagnostics[def.Document], protocol.Diagnostic{
+		Severity:           severity,
+		Source:             "no-duplicate-definition",
+		Message:            message,
+		Range:              def.Range(),
+		RelatedInformation: related,
+	})
+}
+
+// onlyListsTasks reports whether a build variant can be merged into another
+// definition of the same variant, which evergreen allows when it only sets its
+// name, tasks and display tasks
+func onlyListsTasks(node ast.Node) bool {
+	m, ok := node.(*ast.MappingNode)
+	if !ok {
+		return false
+	}
+	hasTasks := false
+	for _, v := range m.Values {
+		switch v.Key.GetToken().Value {
+		case "name":
+		case "tasks", "display_tasks":
+			hasTasks = true
+		default:
+			return false
+		}
+	}
+	return hasTasks
+}
+
+// displayPath returns a document's path relative to the project root for use
+// in messages
+func displayPath(d *project.Document) string {
+	path := d.URI.Filename()
+	if rel, err := filepath.Rel(d.Workspace.Root(), path); err == nil {
+		return rel
+	}
+	return path
+}
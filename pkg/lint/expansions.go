@@ -0,0 +1,255 @@
+package lint
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/util"
+)
+
+// expansionPattern matches ${name} and ${name|default}
+var expansionPattern = regexp.MustCompile(`\$\{([^}|]+)(?:\|([^}]*))?\}`)
+
+// assumeRoleExpansions are set by ec2.assume_role
+var assumeRoleExpansions = []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_ROLE_EXPIRATION"}
+
+type UndefinedExpansionLinter struct {
+	executor *Executor
+	defined  map[string]struct{}
+	// fromFile is set when the project loads expansions from a file, whose
+	// keys are only known when listed in KnownExpansions
+	fromFile bool
+	options  struct {
+		// KnownExpansions are expansions set outside of the project config,
+		// such as project variables, in addition to DefaultKnownExpansions
+		KnownExpansions []string `yaml:"known_expansions"`
+	}
+}
+
+func (l *UndefinedExpansionLinter) Register(executor *Executor) {
+	l.executor = executor
+	executor.ruleOptions("no-undefined-expansion", &l.options)
+	known := append(slices.Clone(config.DefaultKnownExpansions), l.options.KnownExpansions...)
+	l.defined, l.fromFile = definedExpansions(executor.workspace.Data, known)
+}
+
+func (l *UndefinedExpansionLinter) Enabled(_ config.Lint) bool {
+	return true
+}
+
+func (l *UndefinedExpansionLinter) Check(node ast.Node) []protocol.Diagnostic {
+	diagnostics := []protocol.Diagnostic{}
+	// Evergreen applies expansions to the params of commands and the vars of
+	// function calls
+	var expanded ast.Node
+	switch {
+	case mappingEntry(node, "command") != nil:
+		expanded = MappingField(node, "params")
+	case mappingEntry(node, "func") != nil:
+		expanded = MappingField(node, "vars")
+	default:
+		return diagnostics
+	}
+	for _, use := range expansionUses(expanded) {
+		if _, ok := l.defined[use.name]; ok {
+			continue
+		}
+		suggestion, hasSuggestion := util.ClosestMatch(use.name, slices.Sorted(maps.Keys(l.defined)))
+		switch {
+		case !use.hasDefault:
+			message := fmt.Sprintf("expansion %q is never set", use.name)
+			switch {
+			case hasSuggestion:
+				message += fmt.Sprintf(", did you mean %q?", suggestion)
+			case l.fromFile:
+				message += ", add it to the known_expansions option if a file loaded with expansions.update sets it"
+			}
+			diagnostics = append(diagnostics, protocol.Diagnostic{
+				Severity: protocol.DiagnosticSeverityWarning,
+				Source:   "no-undefined-expansion",
+				Message:  message,
+				Range:    use.rng,
+			})
+		case hasSuggestion && !l.fromFile:
+			// Defaults are commonly used for optional expansions, so only
+			// report them when the name looks like a typo of one that is set.
+			// A file could set the name as written, so it is left alone then.
+			diagnostics = append(diagnostics, protocol.Diagnostic{
+				Severity: protocol.DiagnosticSeverityWarning,
+				Source:   "no-undefined-expansion",
+				Message:  fmt.Sprintf("expansion %q is never set so its default is always used, did you mean %q?", use.name, suggestion),
+				Range:    use.rng,
+			})
+		}
+	}
+	return diagnostics
+}
+
+// expansionUse is a single ${name} or ${name|default} in a value
+type expansionUse struct {
+	name       string
+	hasDefault bool
+	rng        protocol.Range
+}
+
+// expansionUses returns every expansion used in the values of a node
+func expansionUses(node ast.Node) []expansionUse {
+	uses := []expansionUse{}
+	switch n := UnwrapAnchor(node).(type) {
+	case *ast.MappingNode:
+		for _, v := range n.Values {
+			uses = append(uses, expansionUses(v.Value)...)
+		}
+	case *ast.MappingValueNode:
+		uses = append(uses, expansionUses(n.Value)...)
+	case *ast.SequenceNode:
+		for _, v := range n.Values {
+			uses = append(uses, expansionUses(v)...)
+		}
+	case *ast.LiteralNode:
+		uses = append(uses, literalExpansionUses(n)...)
+	case *ast.StringNode:
+		uses = append(uses, scalarExpansionUses(n)...)
+	}
+	return uses
+}
+
+// literalExpansionUses finds expansions in a block scalar. The parser gives
+// the content no usable position, so the raw lines are searched instead,
+// starting on the line after the block's header.
+func literalExpansionUses(n *ast.LiteralNode) []expansionUse {
+	uses := []expansionUse{}
+	if n.Value == nil {
+		return uses
+	}
+	header := n.Start.Position.Line // 1-based, so also the 0-based first content line
+	for i, line := range strings.Split(n.Value.GetToken().Origin, "\n") {
+		for _, m := range expansionPattern.FindAllStringSubmatchIndex(line, -1) {
+			start := protocol.Position{
+				Line:      uint32(header + i),                          //nolint:gosec
+				Character: uint32(utf8.RuneCountInString(line[:m[0]])), //nolint:gosec
+			}
+			uses = append(uses, newExpansionUse(line, m, start))
+		}
+	}
+	return uses
+}
+
+// scalarExpansionUses finds expansions in a flow scalar. Positions are exact
+// when the value is written as is, otherwise every use is reported at the
+// start of the value.
+func scalarExpansionUses(n *ast.StringNode) []expansionUse {
+	uses := []expansionUse{}
+	t := n.GetToken()
+	if t.Position.Line < 1 || t.Position.Column < 1 {
+		return uses
+	}
+	valueStart, exact := NameStart(n)
+	if !exact {
+		valueStart = protocol.Position{
+			Line:      uint32(t.Position.Line - 1),   //nolint:gosec
+			Character: uint32(t.Position.Column - 1), //nolint:gosec
+		}
+	}
+	for _, m := range expansionPattern.FindAllStringSubmatchIndex(n.Value, -1) {
+		start := valueStart
+		if exact {
+			start.Character += uint32(utf8.RuneCountInString(n.Value[:m[0]])) //nolint:gosec
+		}
+		uses = append(uses, newExpansionUse(n.Value, m, start))
+	}
+	return uses
+}
+
+func newExpansionUse(text string, match []int, start protocol.Position) expansionUse {
+	end := start
+	end.Character += uint32(utf8.RuneCountInString(text[match[0]:match[1]])) //nolint:gosec
+	return expansionUse{
+		name:       strings.TrimSpace(text[match[2]:match[3]]),
+		hasDefault: match[4] >= 0,
+		rng:        protocol.Range{Start: start, End: end},
+	}
+}
+
+// definedExpansions collects every expansion the project sets anywhere, without
+// regard to whether it is set before it is read. It also reports whether
+// expansions are loaded from a file, in which case any expansion can be set.
+func definedExpansions(data *model.Project, known []string) (map[string]struct{}, bool) {
+	defined := make(map[string]struct{})
+	fromFile := false
+	for _, e := range known {
+		defined[e] = struct{}{}
+	}
+	for _, p := range data.Parameters {
+		defined[p.Key] = struct{}{}
+	}
+	for _, m := range data.Modules {
+		defined[m.Name+"_rev"] = struct{}{}
+	}
+	for _, bv := range data.BuildVariants {
+		for k := range bv.Expansions {
+			defined[k] = struct{}{}
+		}
+	}
+	for _, c := range projectCommands(data) {
+		for k := range c.Vars {
+			defined[k] = struct{}{}
+		}
+		switch c.Command {
+		case "expansions.update":
+			if file, ok := c.Params["file"].(string); ok && file != "" {
+				fromFile = true
+			}
+			updates, _ := c.Params["updates"].([]any)
+			for _, u := range updates {
+				if update, ok := u.(map[string]any); ok {
+					if key, ok := update["key"].(string); ok {
+						defined[key] = struct{}{}
+					}
+				}
+			}
+		case "github.generate_token":
+			if name, ok := c.Params["expansion_name"].(string); ok {
+				defined[name] = struct{}{}
+			}
+		case "keyval.inc":
+			if name, ok := c.Params["destination"].(string); ok {
+				defined[name] = struct{}{}
+			}
+		case "ec2.assume_role":
+			for _, e := range assumeRoleExpansions {
+				defined[e] = struct{}{}
+			}
+		}
+	}
+	return defined, fromFile
+}
+
+// projectCommands returns every command and function call in the project
+func projectCommands(data *model.Project) []model.PluginCommandConf {
+	commands := []model.PluginCommandConf{}
+	sets := []*model.YAMLCommandSet{data.Pre, data.Post, data.Timeout}
+	for _, f := range data.Functions {
+		sets = append(sets, f)
+	}
+	for _, tg := range data.TaskGroups {
+		sets = append(sets, tg.SetupGroup, tg.TeardownGroup, tg.SetupTask, tg.TeardownTask, tg.Timeout)
+	}
+	for _, s := range sets {
+		if s != nil {
+			commands = append(commands, s.List()...)
+		}
+	}
+	for _, t := range data.Tasks {
+		commands = append(commands, t.Commands...)
+	}
+	return commands
+}
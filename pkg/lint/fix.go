@@ -0,0 +1,149 @@
+package lint
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// Fix is a machine-applicable change that resolves a diagnostic. Linters attach
+// it as the diagnostic's Data.
+type Fix struct {
+	Title string              `json:"title"`
+	Edits []protocol.TextEdit `json:"edits"`
+}
+
+// FixOf returns the fix attached to a diagnostic, if any
+func FixOf(d protocol.Diagnostic) (*Fix, bool) {
+	fix, ok := d.Data.(*Fix)
+	return fix, ok && len(fix.Edits) > 0
+}
+
+// ApplyFixes applies every fix whose edits do not overlap the edits of a fix
+// applied before it and returns the new text along with the number of fixes
+// applied. Skipped fixes can be applied by linting the new text again.
+func ApplyFixes(text string, fixes []*Fix) (string, int) {
+	fixes = slices.Clone(fixes)
+	slices.SortStableFunc(fixes, func(a *Fix, b *Fix) int {
+		return comparePositions(firstEdit(a).Range.Start, firstEdit(b).Range.Start)
+	})
+
+	accepted := []protocol.TextEdit{}
+	applied := 0
+	for _, fix := range fixes {
+		overlaps := slices.ContainsFunc(fix.Edits, func(e protocol.TextEdit) bool {
+			return slices.ContainsFunc(accepted, func(a protocol.TextEdit) bool {
+				return rangesOverlap(e.Range, a.Range)
+			})
+		})
+		if overlaps {
+			continue
+		}
+		accepted = append(accepted, fix.Edits...)
+		applied++
+	}
+
+	// Apply from the end of the text so earlier positions stay valid
+	slices.SortStableFunc(accepted, func(a protocol.TextEdit, b protocol.TextEdit) int {
+		return comparePositions(b.Range.Start, a.Range.Start)
+	})
+	lines := strings.SplitAfter(text, "\n")
+	for _, e := range accepted {
+		start := positionOffset(lines, e.Range.Start)
+		end := positionOffset(lines, e.Range.End)
+		text = text[:start] + e.NewText + text[end:]
+		lines = strings.SplitAfter(text, "\n")
+	}
+	return text, applied
+}
+
+func firstEdit(fix *Fix) protocol.TextEdit {
+	return slices.MinFunc(fix.Edits, func(a protocol.TextEdit, b protocol.TextEdit) int {
+		return comparePositions(a.Range.Start, b.Range.Start)
+	})
+}
+
+func comparePositions(a protocol.Position, b protocol.Position) int {
+	if a.Line != b.Line {
+		return int(a.Line) - int(b.Line)
+	}
+	return int(a.Character) - int(b.Character)
+}
+
+func rangesOverlap(a protocol.Range, b protocol.Range) bool {
+	return (comparePositions(a.Start, b.End) < 0 && comparePositions(b.Start, a.End) < 0) ||
+		comparePositions(a.Start, b.Start) == 0
+}
+
+// positionOffset converts a position into a byte offset. Characters are
+// counted in runes to match the columns of the YAML parser.
+func positionOffset(lines []string, p protocol.Position) int {
+	offset := 0
+	for i := 0; i < int(p.Line) && i < len(lines); i++ {
+		offset += len(lines[i])
+	}
+	if int(p.Line) >= len(lines) {
+		return offset
+	}
+	line := strings.TrimSuffix(lines[p.Line], "\n")
+	runes := 0
+	for i := range line {
+		if runes == int(p.Character) {
+			return offset + i
+		}
+		runes++
+	}
+	return offset + len(line)
+}
+
+// replaceNodeEdit replaces the value of a scalar node, keeping its quotes. It
+// is only possible when the value is written as is, without escapes or
+// folding, since the parser does not keep the end of a node.
+func replaceNodeEdit(node ast.Node, newText string) (protocol.TextEdit, bool) {
+	start, ok := NameStart(node)
+	if !ok {
+		return protocol.TextEdit{}, false
+	}
+	end := start
+	end.Character += uint32(len([]rune(node.(*ast.StringNode).Value))) //nolint:gosec,forcetypeassert // NameStart only accepts strings
+	return protocol.TextEdit{
+		Range:   protocol.Range{Start: start, End: end},
+		NewText: newText,
+	}, true
+}
+
+// FixedDocument is the new text of a document after applying fixes
+type FixedDocument struct {
+	Document *project.Document
+	Text     string
+	Fixes    int
+}
+
+// Fix lints the project and applies the fixes attached to its diagnostics,
+// returning the documents that changed. Nothing is written to disk.
+func (e *Executor) Fix() ([]FixedDocument, error) {
+	diagnostics, err := e.Lint()
+	if err != nil {
+		return nil, err
+	}
+	fixed := []FixedDocument{}
+	for _, d := range e.workspace.Documents() {
+		fixes := []*Fix{}
+		for _, diag := range diagnostics[d] {
+			if fix, ok := FixOf(diag); ok {
+				fixes = append(fixes, fix)
+			}
+		}
+		if len(fixes) == 0 {
+			continue
+		}
+		text, applied := ApplyFixes(d.Text, fixes)
+		if applied > 0 && text != d.Text {
+			fixed = append(fixed, FixedDocument{Document: d, Text: text, Fixes: applied})
+		}
+	}
+	return fixed, nil
+}
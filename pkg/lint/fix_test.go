@@ -0,0 +1,126 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/a-h/templ/lsp/protocol"
+)
+
+// edit builds an edit replacing the text between two positions
+func edit(startLine, startChar, endLine, endChar uint32, newText string) protocol.TextEdit {
+	return protocol.TextEdit{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: startChar},
+			End:   protocol.Position{Line: endLine, Character: endChar},
+		},
+		NewText: newText,
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		fixes       []*Fix
+		want        string
+		wantApplied int
+	}{
+		{
+			name:        "no fixes",
+			text:        "a: b\n",
+			fixes:       []*Fix{},
+			want:        "a: b\n",
+			wantApplied: 0,
+		},
+		{
+			name: "single edit",
+			text: "command: shell.exce\n",
+			fixes: []*Fix{
+				{Title: "rename", Edits: []protocol.TextEdit{edit(0, 9, 0, 19, "shell.exec")}},
+			},
+			want:        "command: shell.exec\n",
+			wantApplied: 1,
+		},
+		{
+			name: "overlapping fixes keep the first",
+			text: "abcdef\n",
+			fixes: []*Fix{
+				{Title: "second", Edits: []protocol.TextEdit{edit(0, 2, 0, 5, "Y")}},
+				{Title: "first", Edits: []protocol.TextEdit{edit(0, 0, 0, 3, "X")}},
+			},
+			want:        "Xdef\n",
+			wantApplied: 1,
+		},
+		{
+			name: "fixes starting at the same position overlap",
+			text: "abc\n",
+			fixes: []*Fix{
+				{Title: "insert", Edits: []protocol.TextEdit{edit(0, 1, 0, 1, "X")}},
+				{Title: "insert again", Edits: []protocol.TextEdit{edit(0, 1, 0, 1, "Y")}},
+			},
+			want:        "aXbc\n",
+			wantApplied: 1,
+		},
+		{
+			name: "adjacent edits are both applied",
+			text: "abcdef\n",
+			fixes: []*Fix{
+				{Title: "tail", Edits: []protocol.TextEdit{edit(0, 3, 0, 6, "DEF")}},
+				{Title: "head", Edits: []protocol.TextEdit{edit(0, 0, 0, 3, "ABC")}},
+			},
+			want:        "ABCDEF\n",
+			wantApplied: 2,
+		},
+		{
+			name: "fixes are ordered by their first edit",
+			text: "one\ntwo\nthree\n",
+			fixes: []*Fix{
+				{Title: "line", Edits: []protocol.TextEdit{edit(1, 0, 1, 3, "TWO")}},
+				{Title: "both", Edits: []protocol.TextEdit{edit(0, 0, 0, 3, "ONE"), edit(1, 1, 1, 2, "W")}},
+				{Title: "last", Edits: []protocol.TextEdit{edit(2, 0, 2, 5, "THREE")}},
+			},
+			want:        "ONE\ntWo\nTHREE\n",
+			wantApplied: 2,
+		},
+		{
+			name: "a fix is skipped when any of its edits overlaps",
+			text: "one\ntwo\nthree\n",
+			fixes: []*Fix{
+				{Title: "first", Edits: []protocol.TextEdit{edit(0, 0, 0, 3, "ONE")}},
+				{Title: "second", Edits: []protocol.TextEdit{edit(2, 0, 2, 5, "THREE"), edit(0, 1, 0, 2, "N")}},
+				{Title: "third", Edits: []protocol.TextEdit{edit(1, 0, 1, 3, "TWO")}},
+			},
+			want:        "ONE\nTWO\nthree\n",
+			wantApplied: 2,
+		},
+		{
+			name: "edits spanning lines",
+			text: "a\nb\nc\n",
+			fixes: []*Fix{
+				{Title: "join", Edits: []protocol.TextEdit{edit(0, 1, 2, 0, " ")}},
+			},
+			want:        "a c\n",
+			wantApplied: 1,
+		},
+		{
+			name: "characters are counted in runes",
+			text: "name: \"ünïcode tsk\"\n",
+			fixes: []*Fix{
+				{Title: "rename", Edits: []protocol.TextEdit{edit(0, 15, 0, 18, "task")}},
+			},
+			want:        "name: \"ünïcode task\"\n",
+			wantApplied: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, applied := ApplyFixes(tt.text, tt.fixes)
+			if got != tt.want {
+				t.Errorf("ApplyFixes() text = %q, want %q", got, tt.want)
+			}
+			if applied != tt.wantApplied {
+				t.Errorf("ApplyFixes() applied = %d, want %d", applied, tt.wantApplied)
+			}
+		})
+	}
+}
@@ -1,6 +1,13 @@
 package lint
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
 	"github.com/a-h/templ/lsp/protocol"
 	"github.com/goccy/go-yaml/ast"
 	"github.com/lavigneer/evergreen-lsp/pkg/config"
@@ -9,24 +16,174 @@ import (
 
 type ExecutorDiagnostics map[*project.Document][]protocol.Diagnostic
 
+// Documents returns the documents that have diagnostics sorted by URI, so
+// results are reported in the same order on every run
+func (d ExecutorDiagnostics) Documents() []*project.Document {
+	docs := make([]*project.Document, 0, len(d))
+	for doc := range d {
+		docs = append(docs, doc)
+	}
+	slices.SortFunc(docs, func(a *project.Document, b *project.Document) int {
+		return cmp.Compare(a.URI, b.URI)
+	})
+	return docs
+}
+
+// SortDiagnostics orders diagnostics by position, then rule and message
+func SortDiagnostics(diagnostics []protocol.Diagnostic) {
+	slices.SortStableFunc(diagnostics, func(a protocol.Diagnostic, b protocol.Diagnostic) int {
+		return cmp.Or(
+			comparePositions(a.Range.Start, b.Range.Start),
+			cmp.Compare(a.Source, b.Source),
+			cmp.Compare(a.Message, b.Message),
+		)
+	})
+}
+
 type Executor struct {
 	workspace   *project.Project
 	settings    config.Lint
 	diagnostics ExecutorDiagnostics
-	linters     []Linter
+	rules       []activeRule
+	pool        *Pool
 }
 
+// Linter is called for every node of every document in a project
 type Linter interface {
 	Check(node ast.Node) []protocol.Diagnostic
 	Enabled(settings config.Lint) bool
 	Register(executor *Executor)
 }
 
-var linters = []Linter{
-	&DeprecatedLinter{},
-	&UndefinedLinter{},
-	&EnforceTagsLinter{},
-	&NoInlineScriptsLinter{},
+// Rule is a linter that can be configured by its ID, which is also the source
+// of every diagnostic it reports. Exactly one of New and NewProject is set.
+type Rule struct {
+	ID string
+	// Description is a one line summary of what the rule reports
+	Description string
+	// Help explains why the rule matters and how to resolve its diagnostics
+	Help string
+	// Severity is what most of the rule's diagnostics are reported with
+	Severity   protocol.DiagnosticSeverity
+	New        func() Linter
+	NewProject func() ProjectLinter
+}
+
+var Rules = []Rule{
+	{
+		ID:          "deprecated-command",
+		Description: "Commands that evergreen has deprecated",
+		Help:        "Deprecated commands may be removed from evergreen. Replace shell.exec with subprocess.exec, which --fix can do for most uses.",
+		Severity:    protocol.DiagnosticSeverityWarning,
+		New:         func() Linter { return &DeprecatedLinter{} },
+	},
+	{
+		ID:          "no-undefined",
+		Description: "References to functions, tasks, task groups and build variants that are not defined",
+		Help:        "Evergreen fails to load projects that reference undefined entities. Define the entity or fix the name, which --fix can do when there is a close match.",
+		Severity:    protocol.DiagnosticSeverityError,
+		New:         func() Linter { return &UndefinedLinter{} },
+	},
+	{
+		ID:          "enforce-tags",
+		Description: "Task references that use a name instead of a tag selector",
+		Help:        "Selecting tasks by tag keeps build variants up to date as tasks are added. Tag the task and select it with .tag, or allow the name in the rule options.",
+		Severity:    protocol.DiagnosticSeverityWarning,
+		New:         func() Linter { return &EnforceTagsLinter{} },
+	},
+	{
+		ID:          "no-inline-script",
+		Description: "Scripts written inline in the project config",
+		Help:        "Inline scripts cannot be linted or run locally. Move the script into a file in the repository and run it with subprocess.exec.",
+		Severity:    protocol.DiagnosticSeverityWarning,
+		New:         func() Linter { return &NoInlineScriptsLinter{} },
+	},
+	{
+		ID:          "no-unused-function",
+		Description: "Functions that are never called",
+		Help:        "Unused functions make the project harder to maintain. Remove the function or call it.",
+		Severity:    protocol.DiagnosticSeverityWarning,
+		NewProject:  func() ProjectLinter { return &UnusedFunctionLinter{} },
+	},
+	{
+		ID:          "no-dependency-cycle",
+		Description: "Tasks that depend on each other in a cycle",
+		Help:        "Tasks in a dependency cycle can never be scheduled. Remove one of the depends_on entries that form the cycle.",
+		Severity:    protocol.DiagnosticSeverityError,
+		NewProject:  func() ProjectLinter { return &DependencyCycleLinter{} },
+	},
+	{
+		ID:          "no-unmatched-selector",
+		Description: "Tag selectors that do not match any task or task group",
+		Help:        "A selector that matches nothing usually means a tag was renamed or removed. Fix the selector or tag the intended tasks.",
+		Severity:    protocol.DiagnosticSeverityWarning,
+		New:         func() Linter { return &UnmatchedSelectorLinter{} },
+	},
+	{
+		ID:          "command-params",
+		Description: "Command parameters that are missing, unknown or of the wrong type",
+		Help:        "Evergreen fails commands with invalid parameters when the task runs. Check the parameters against the command's documentation.",
+		Severity:    protocol.DiagnosticSeverityError,
+		New:         func() Linter { return &CommandParamsLinter{} },
+	},
+	{
+		ID:          "no-undefined-expansion",
+		Description: "Expansions that are never defined",
+		Help:        "Undefined expansions are replaced with an empty string. Define the expansion, give it a default with ${name|default}, or list it in the rule's known_expansions option, which is also where keys of files loaded with expansions.update go.",
+		Severity:    protocol.DiagnosticSeverityWarning,
+		New:         func() Linter { return &UndefinedExpansionLinter{} },
+	},
+	{
+		ID:          "no-duplicate-definition",
+		Description: "Tasks, task groups, functions and build variants defined more than once",
+		Help:        "Evergreen rejects most duplicate definitions and only merges the tasks of duplicate build variants. Remove or rename all but one definition.",
+		Severity:    protocol.DiagnosticSeverityError,
+		NewProject:  func() ProjectLinter { return &DuplicateDefinitionLinter{} },
+	},
+	{
+		ID:          "no-unknown-distro",
+		Description: "Distros that are not in the distro catalog or are retired",
+		Help:        "Tasks on unknown distros are never scheduled. Use a distro from the catalog configured in distro_catalog.",
+		Severity:    protocol.DiagnosticSeverityError,
+		New:         func() Linter { return &UnknownDistroLinter{} },
+	},
+	{
+		ID:          "timing",
+		Description: "Invalid or suspicious timeouts, batchtimes and cron schedules",
+		Help:        "Negative or malformed values fail to load, and very long timeouts hold hosts for hours. Fix the value or raise max_timeout_secs in the rule options. timeout.update is checked against the longest exec timeout of the tasks that run it, which is not known for commands in pre, post, timeout or task group blocks, or for functions called from them, so those are not checked.",
+		Severity:    protocol.DiagnosticSeverityError,
+		New:         func() Linter { return &TimingLinter{} },
+	},
+}
+
+// ruleLinter is what Linter and ProjectLinter have in common
+type ruleLinter interface {
+	Enabled(settings config.Lint) bool
+	Register(executor *Executor)
+}
+
+// activeRule is a linter that is enabled for an executor along with the
+// severity its diagnostics are overridden with, if any. Only one of linter
+// and projectLinter is set.
+type activeRule struct {
+	id            string
+	linter        Linter
+	projectLinter ProjectLinter
+	severity      protocol.DiagnosticSeverity
+}
+
+// apply overrides the severity of a diagnostic when the config sets one
+func (r activeRule) apply(d protocol.Diagnostic) protocol.Diagnostic {
+	if r.severity != 0 {
+		d.Severity = r.severity
+	}
+	return d
+}
+
+var severities = map[config.Severity]protocol.DiagnosticSeverity{
+	config.SeverityInfo:    protocol.DiagnosticSeverityInformation,
+	config.SeverityWarning: protocol.DiagnosticSeverityWarning,
+	config.SeverityError:   protocol.DiagnosticSeverityError,
 }
 
 func New(workspace *project.Project, settings config.Lint) *Executor {
@@ -34,50 +191,185 @@ func New(workspace *project.Project, settings config.Lint) *Executor {
 		workspace:   workspace,
 		settings:    settings,
 		diagnostics: make(map[*project.Document][]protocol.Diagnostic),
-		linters:     linters,
+		pool:        NewPool(settings.Jobs),
 	}
-	for _, l := range executor.linters {
-		l.Register(executor)
+	isBuiltIn := func(id string) bool {
+		return slices.ContainsFunc(Rules, func(r Rule) bool { return r.ID == id })
+	}
+	for id := range settings.Rules {
+		isCustom := slices.ContainsFunc(settings.CustomRules, func(r config.CustomRule) bool { return r.ID == id })
+		if !isBuiltIn(id) && !isCustom {
+			slog.Warn("Unknown lint rule in config", "rule", id)
+		}
+	}
+	for _, r := range Rules {
+		if r.NewProject != nil {
+			executor.addRule(r.ID, r.NewProject())
+		} else {
+			executor.addRule(r.ID, r.New())
+		}
+	}
+	for _, r := range settings.CustomRules {
+		if isBuiltIn(r.ID) {
+			slog.Warn("Custom lint rule has the ID of a built-in rule", "rule", r.ID)
+			continue
+		}
+		if r.Severity == config.SeverityOff {
+			continue
+		}
+		linter, err := NewCustomRuleLinter(r)
+		if err != nil {
+			slog.Warn("Invalid custom lint rule", "rule", r.ID, "error", err)
+			continue
+		}
+		executor.addRule(r.ID, linter)
 	}
 
 	return executor
 }
 
+// addRule registers a Linter or ProjectLinter unless it is turned off in the
+// config
+func (e *Executor) addRule(id string, linter ruleLinter) {
+	ruleSettings := e.settings.Rule(id)
+	if ruleSettings.Severity == config.SeverityOff || !linter.Enabled(e.settings) {
+		return
+	}
+	linter.Register(e)
+	rule := activeRule{
+		id:       id,
+		severity: severities[ruleSettings.Severity],
+	}
+	switch l := linter.(type) {
+	case Linter:
+		rule.linter = l
+	case ProjectLinter:
+		rule.projectLinter = l
+	}
+	e.rules = append(e.rules, rule)
+}
+
+// SetPool makes the executor share a pool with others, so that linting
+// several projects at once stays within a single bound
+func (e *Executor) SetPool(pool *Pool) {
+	e.pool = pool
+}
+
+// ruleOptions decodes the options configured for a rule into v, leaving the
+// defaults in place when the options are invalid
+func (e *Executor) ruleOptions(id string, v any) {
+	if err := e.settings.Rule(id).DecodeOptions(v); err != nil {
+		slog.Warn("Invalid lint rule options", "rule", id, "error", err)
+	}
+}
+
+// Lint lints every document of the project, running up to the pool's number
+// of documents at once. Documents that are not valid YAML are skipped and
+// returned as an error along with the diagnostics of the others.
 func (e *Executor) Lint() (ExecutorDiagnostics, error) {
-	for _, v := range e.workspace.TextDocuments {
-		visitor := Visitor{
-			diagnostics: make([]protocol.Diagnostic, 0),
-			linters:     e.linters,
+	e.diagnostics = make(ExecutorDiagnostics)
+	var errs []error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, v := range e.workspace.Documents() {
+		if v.ParseError != nil {
+			errs = append(errs, fmt.Errorf("parsing %s: %w", v.URI.Filename(), v.ParseError))
+		}
+		root := v.RootNode()
+		if root == nil {
+			continue
 		}
-		ast.Walk(&visitor, v.RootNode())
-		e.diagnostics[v] = visitor.diagnostics
+		e.pool.Go(&wg, func() {
+			diagnostics := e.checkNodes(root)
+			mu.Lock()
+			defer mu.Unlock()
+			e.diagnostics[v] = append(e.diagnostics[v], diagnostics...)
+		})
+	}
+	e.pool.Go(&wg, func() {
+		diagnostics := e.checkProject()
+		mu.Lock()
+		defer mu.Unlock()
+		for doc, diags := range diagnostics {
+			e.diagnostics[doc] = append(e.diagnostics[doc], diags...)
+		}
+	})
+	wg.Wait()
 
+	for doc, diagnostics := range e.diagnostics {
+		diagnostics = filterSuppressed(doc, diagnostics)
+		SortDiagnostics(diagnostics)
+		e.diagnostics[doc] = diagnostics
 	}
-	return e.diagnostics, nil
+	return e.diagnostics, errors.Join(errs...)
 }
 
-func (e *Executor) LintDocument(doc protocol.DocumentURI) ([]protocol.Diagnostic, error) {
-	if d, ok := e.workspace.TextDocuments[doc]; ok {
-		visitor := Visitor{
-			diagnostics: make([]protocol.Diagnostic, 0),
-			linters:     e.linters,
+// LintDocuments lints the given documents of the project. The project linters
+// still look at every document, so the result has an entry for each of the
+// given documents even when it has no diagnostics, so that diagnostics found
+// in another document before are cleared.
+func (e *Executor) LintDocuments(docs []protocol.DocumentURI) ExecutorDiagnostics {
+	diagnostics := make(ExecutorDiagnostics)
+	projectDiagnostics := e.checkProject()
+	for _, uri := range docs {
+		d, ok := e.workspace.TextDocuments[uri]
+		if !ok {
+			continue
+		}
+		docDiagnostics := []protocol.Diagnostic{}
+		if root := d.RootNode(); root != nil {
+			docDiagnostics = append(e.checkNodes(root), projectDiagnostics[d]...)
 		}
-		ast.Walk(&visitor, d.RootNode())
-		return visitor.diagnostics, nil
+		docDiagnostics = filterSuppressed(d, docDiagnostics)
+		SortDiagnostics(docDiagnostics)
+		diagnostics[d] = docDiagnostics
+	}
+	return diagnostics
+}
+
+func (e *Executor) checkNodes(root ast.Node) []protocol.Diagnostic {
+	visitor := Visitor{
+		diagnostics: make([]protocol.Diagnostic, 0),
+		rules:       e.rules,
+	}
+	ast.Walk(&visitor, root)
+	return visitor.diagnostics
+}
 
+// checkProject runs the project linters, which only needs to happen once
+// however many documents are linted
+func (e *Executor) checkProject() ExecutorDiagnostics {
+	diagnostics := make(ExecutorDiagnostics)
+	var projectContext *ProjectContext
+	for _, rule := range e.rules {
+		if rule.projectLinter == nil {
+			continue
+		}
+		if projectContext == nil {
+			projectContext = newProjectContext(e.workspace)
+		}
+		for doc, diags := range rule.projectLinter.CheckProject(projectContext) {
+			for _, d := range diags {
+				diagnostics[doc] = append(diagnostics[doc], rule.apply(d))
+			}
+		}
 	}
-	return nil, nil
+	return diagnostics
 }
 
 type Visitor struct {
 	diagnostics []protocol.Diagnostic
-	linters     []Linter
+	rules       []activeRule
 }
 
 func (l *Visitor) Visit(node ast.Node) ast.Visitor {
-	for _, linter := range l.linters {
-		linterDiagnostics := linter.Check(node)
-		l.diagnostics = append(l.diagnostics, linterDiagnostics...)
+	for _, rule := range l.rules {
+		if rule.linter == nil {
+			continue
+		}
+		for _, d := range rule.linter.Check(node) {
+			l.diagnostics = append(l.diagnostics, rule.apply(d))
+		}
 	}
 	return l
 }
@@ -0,0 +1,101 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// SequenceEntries returns the entries of a list, treating a single value as a
+// list of one since evergreen accepts both forms
+func SequenceEntries(node ast.Node) []ast.Node {
+	node = UnwrapAnchor(node)
+	if s, ok := node.(*ast.SequenceNode); ok {
+		return s.Values
+	}
+	return []ast.Node{node}
+}
+
+// EntryField returns the given field of a list entry, or the entry itself when
+// it uses the shorthand string form
+func EntryField(node ast.Node, field string) ast.Node {
+	node = UnwrapAnchor(node)
+	if s, ok := node.(*ast.StringNode); ok {
+		return s
+	}
+	return MappingField(node, field)
+}
+
+// MappingField returns the value of a field of a mapping, or nil when the node
+// is not a mapping or does not have the field
+func MappingField(node ast.Node, field string) ast.Node {
+	if v := mappingEntry(node, field); v != nil {
+		return UnwrapAnchor(v.Value)
+	}
+	return nil
+}
+
+func mappingEntry(node ast.Node, field string) *ast.MappingValueNode {
+	m, ok := UnwrapAnchor(node).(*ast.MappingNode)
+	if !ok {
+		return nil
+	}
+	for _, v := range m.Values {
+		if v.Key.GetToken().Value == field {
+			return v
+		}
+	}
+	return nil
+}
+
+// UnwrapAnchor returns the value of an anchored node
+func UnwrapAnchor(node ast.Node) ast.Node {
+	if a, ok := node.(*ast.AnchorNode); ok {
+		return a.Value
+	}
+	return node
+}
+
+// findNamedEntry returns the entry of a list whose name matches, accepting both
+// the mapping and shorthand string forms
+func findNamedEntry(node ast.Node, name string) ast.Node {
+	if node == nil {
+		return nil
+	}
+	for _, entry := range SequenceEntries(node) {
+		if n := EntryField(entry, "name"); n != nil && n.GetToken().Value == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+// topLevelField returns the value of a top level key in a document
+func topLevelField(d *project.Document, field string) ast.Node {
+	if d.AST == nil || len(d.AST.Docs) == 0 {
+		return nil
+	}
+	return MappingField(d.RootNode(), field)
+}
+
+// NameStart returns where the value of a string node starts. It is only
+// possible when the value is written as is, without escapes or folding, since
+// the parser does not keep the end of a node.
+func NameStart(node ast.Node) (protocol.Position, bool) {
+	s, ok := node.(*ast.StringNode)
+	if !ok || strings.ContainsAny(s.Value, "\\\"'\n") {
+		return protocol.Position{}, false
+	}
+	t := s.GetToken()
+	start := protocol.Position{
+		Line:      uint32(t.Position.Line - 1),   //nolint:gosec
+		Character: uint32(t.Position.Column - 1), //nolint:gosec
+	}
+	if t.Type == token.DoubleQuoteType || t.Type == token.SingleQuoteType {
+		start.Character++
+	}
+	return start, true
+}
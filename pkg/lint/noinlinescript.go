@@ -17,8 +17,8 @@ func (l *NoInlineScriptsLinter) Register(executor *Executor) {
 	l.executor = executor
 }
 
-func (l *NoInlineScriptsLinter) Enabled(settings config.Lint) bool {
-	return settings.NoInlineScripts
+func (l *NoInlineScriptsLinter) Enabled(_ config.Lint) bool {
+	return true
 }
 
 func (l *NoInlineScriptsLinter) Check(node ast.Node) []protocol.Diagnostic {
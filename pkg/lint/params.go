@@ -0,0 +1,278 @@
+package lint
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/evergreen-ci/evergreen/agent/command"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/util"
+)
+
+// commandSchema describes the params a command accepts. Accepted keys and
+// their types are derived from the fields the agent decodes params into.
+type commandSchema struct {
+	params   map[string]reflect.Type
+	required []string
+	// weaklyTyped commands decode params leniently so scalar types are not checked
+	weaklyTyped bool
+}
+
+// requiredCommandParams lists params that a command unconditionally rejects when
+// missing. The agent only checks these at runtime so they cannot be derived.
+var requiredCommandParams = map[string][]string{
+	"archive.auto_extract":      {"path"},
+	"archive.auto_pack":         {"target", "source_dir"},
+	"archive.targz_extract":     {"path", "destination"},
+	"archive.targz_pack":        {"target", "source_dir"},
+	"archive.zip_extract":       {"path"},
+	"archive.zip_pack":          {"target", "source_dir"},
+	"attach.artifacts":          {"files"},
+	"attach.results":            {"file_location"},
+	"downstream_expansions.set": {"file"},
+	"ec2.assume_role":           {"role_arn"},
+	"generate.tasks":            {"files"},
+	"git.get_project":           {"directory"},
+	"git.push":                  {"directory"},
+	"github.generate_token":     {"expansion_name"},
+	"gotest.parse_files":        {"files"},
+	"keyval.inc":                {"key", "destination"},
+	"papertrail.trace":          {"key_id", "secret_key", "product", "version", "filenames"},
+	"perf.send":                 {"file"},
+	"s3.get":                    {"remote_file"},
+	"s3.put":                    {"remote_file", "content_type"},
+	"shell.exec":                {"script"},
+}
+
+// weaklyTypedCommands decode params with mapstructure's WeaklyTypedInput or
+// otherwise accept strings for any scalar
+var weaklyTypedCommands = []string{"host.create", "host.list", "papertrail.trace", "s3Copy.copy", "timeout.update"}
+
+var commandSchemas = sync.OnceValue(func() map[string]commandSchema {
+	schemas := make(map[string]commandSchema)
+	for _, name := range command.RegisteredCommandNames() {
+		factory, ok := command.GetCommandFactory(name)
+		if !ok {
+			continue
+		}
+		var target any = factory()
+		// host.create decodes its params into a separate struct
+		if name == "host.create" {
+			target = &apimodels.CreateHost{}
+		}
+		params := make(map[string]reflect.Type)
+		paramsFromStruct(reflect.TypeOf(target), params)
+		if name == "host.create" {
+			params["file"] = reflect.TypeFor[string]()
+		}
+		schemas[name] = commandSchema{
+			params:      params,
+			required:    requiredCommandParams[name],
+			weaklyTyped: slices.Contains(weaklyTypedCommands, name),
+		}
+	}
+	return schemas
+})
+
+// param looks up a param the way mapstructure matches it to a field, trying
+// the exact name first and then ignoring case. It returns the name the param
+// is declared with.
+func (s commandSchema) param(key string) (string, reflect.Type, bool) {
+	if t, ok := s.params[key]; ok {
+		return key, t, true
+	}
+	for name, t := range s.params {
+		if strings.EqualFold(name, key) {
+			return name, t, true
+		}
+	}
+	return key, nil, false
+}
+
+// paramsFromStruct collects the keys mapstructure would decode into a struct
+func paramsFromStruct(t reflect.Type, params map[string]reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		if opts == "squash" {
+			paramsFromStruct(f.Type, params)
+			continue
+		}
+		// mapstructure matches untagged fields by their name, ignoring case
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		params[name] = f.Type
+	}
+}
+
+type CommandParamsLinter struct {
+	executor *Executor
+}
+
+func (l *CommandParamsLinter) Register(executor *Executor) {
+	l.executor = executor
+}
+
+func (l *CommandParamsLinter) Enabled(_ config.Lint) bool {
+	return true
+}
+
+func (l *CommandParamsLinter) Check(node ast.Node) []protocol.Diagnostic {
+	diagnostics := []protocol.Diagnostic{}
+	n, ok := node.(*ast.MappingNode)
+	if !ok {
+		return diagnostics
+	}
+	commandNode := MappingField(n, "command")
+	if commandNode == nil {
+		return diagnostics
+	}
+	commandName := commandNode.GetToken().Value
+	schema, ok := commandSchemas()[commandName]
+	if !ok {
+		// Unknown commands are reported by the undefined linter
+		return diagnostics
+	}
+
+	paramsEntry := mappingEntry(n, "params")
+	var params *ast.MappingNode
+	if paramsEntry != nil {
+		params, ok = UnwrapAnchor(paramsEntry.Value).(*ast.MappingNode)
+		if !ok {
+			// Params coming from an alias cannot be checked
+			return diagnostics
+		}
+	}
+
+	present := []string{}
+	hasMerge := false
+	if params != nil {
+		for _, v := range params.Values {
+			if v.Key.Type() == ast.MergeKeyType {
+				hasMerge = true
+				continue
+			}
+			key := v.Key.GetToken().Value
+			name, t, ok := schema.param(key)
+			present = append(present, name)
+			if !ok {
+				message := fmt.Sprintf("unknown parameter %q for command %q", key, commandName)
+				if suggestion, ok := util.ClosestMatch(key, slices.Sorted(maps.Keys(schema.params))); ok {
+					message += fmt.Sprintf(", did you mean %q?", suggestion)
+				}
+				diagnostics = append(diagnostics, protocol.Diagnostic{
+					Severity: protocol.DiagnosticSeverityWarning,
+					Source:   "command-params",
+					Message:  message,
+					Range:    util.RangeFromNode(v, nil),
+				})
+				continue
+			}
+			if expected, ok := paramTypeMatches(v.Value, t, schema.weaklyTyped); !ok {
+				diagnostics = append(diagnostics, protocol.Diagnostic{
+					Severity: protocol.DiagnosticSeverityError,
+					Source:   "command-params",
+					Message:  fmt.Sprintf("parameter %q for command %q must be %s", key, commandName, expected),
+					Range:    util.RangeFromNode(v.Value, nil),
+				})
+			}
+		}
+	}
+
+	// A merge key could supply anything, so missing params cannot be known
+	if hasMerge {
+		return diagnostics
+	}
+	missing := []string{}
+	for _, r := range schema.required {
+		if !slices.Contains(present, r) {
+			missing = append(missing, r)
+		}
+	}
+	if len(missing) > 0 {
+		rangeNode := ast.Node(commandNode)
+		if paramsEntry != nil {
+			rangeNode = paramsEntry
+		}
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Severity: protocol.DiagnosticSeverityError,
+			Source:   "command-params",
+			Message:  fmt.Sprintf("command %q is missing required parameter(s): %s", commandName, strings.Join(missing, ", ")),
+			Range:    util.RangeFromNode(rangeNode, nil),
+		})
+	}
+	return diagnostics
+}
+
+// paramTypeMatches checks a param value against the type it is decoded into,
+// returning a description of the expected type when it does not fit. Strings
+// containing expansions are always accepted since their value is only known at
+// runtime.
+func paramTypeMatches(node ast.Node, t reflect.Type, weaklyTyped bool) (string, bool) {
+	node = UnwrapAnchor(node)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch node.(type) {
+	case *ast.NullNode, *ast.AliasNode, *ast.TagNode:
+		return "", true
+	}
+	if s, ok := node.(*ast.StringNode); ok && strings.Contains(s.Value, "${") {
+		return "", true
+	}
+
+	_, isSequence := node.(*ast.SequenceNode)
+	_, isMapping := node.(*ast.MappingNode)
+	isScalar := !isSequence && !isMapping
+	switch t.Kind() {
+	case reflect.Interface:
+		return "", true
+	case reflect.Slice, reflect.Array:
+		// Weak decoding wraps a single value into a list
+		return "a list", isSequence || (weaklyTyped && isScalar)
+	case reflect.Map, reflect.Struct:
+		return "a mapping", isMapping
+	case reflect.Bool:
+		if !isScalar {
+			return "a boolean", false
+		}
+		if s, ok := node.(*ast.StringNode); ok && !weaklyTyped {
+			_, err := strconv.ParseBool(s.Value)
+			return "a boolean", err == nil
+		}
+		return "", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !isScalar {
+			return "an integer", false
+		}
+		if s, ok := node.(*ast.StringNode); ok && !weaklyTyped {
+			_, err := strconv.Atoi(s.Value)
+			return "an integer", err == nil
+		}
+		return "", true
+	default:
+		return "a scalar value", isScalar
+	}
+}
@@ -0,0 +1,33 @@
+package lint
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Pool bounds how much linting runs at once. Executors can share a pool so
+// the bound applies across projects.
+type Pool struct {
+	slots chan struct{}
+}
+
+// NewPool returns a pool running up to jobs functions at once, or one per CPU
+// when jobs is not positive
+func NewPool(jobs int) *Pool {
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+	return &Pool{slots: make(chan struct{}, jobs)}
+}
+
+// Go runs fn once a slot is free. Functions run this way must not wait on
+// other functions of the same pool or they can deadlock.
+func (p *Pool) Go(wg *sync.WaitGroup, fn func()) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.slots <- struct{}{}
+		defer func() { <-p.slots }()
+		fn()
+	}()
+}
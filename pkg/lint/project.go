@@ -0,0 +1,139 @@
+package lint
+
+import (
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+	"github.com/lavigneer/evergreen-lsp/pkg/util"
+)
+
+// ProjectLinter runs once per project instead of once per node, for rules
+// that need to see the whole project at once. It can report diagnostics in
+// any of the project's documents.
+type ProjectLinter interface {
+	CheckProject(project *ProjectContext) ExecutorDiagnostics
+	Enabled(settings config.Lint) bool
+	Register(executor *Executor)
+}
+
+// EntityKind is a kind of named entity a project declares
+type EntityKind string
+
+const (
+	EntityTask         EntityKind = "task"
+	EntityTaskGroup    EntityKind = "task group"
+	EntityBuildVariant EntityKind = "build variant"
+	EntityFunction     EntityKind = "function"
+)
+
+// entityFields are the top level lists each kind is declared in, functions
+// being the exception as a mapping of names
+var entityFields = map[EntityKind]string{
+	EntityTask:         "tasks",
+	EntityTaskGroup:    "task_groups",
+	EntityBuildVariant: "buildvariants",
+}
+
+// Definition is a single place an entity is declared
+type Definition struct {
+	Document *project.Document
+	// Name is the node holding the entity's name
+	Name ast.Node
+	// Entry is the node holding the rest of the definition
+	Entry ast.Node
+}
+
+func (d Definition) Range() protocol.Range {
+	return util.RangeFromNode(d.Name, nil)
+}
+
+func (d Definition) Location() protocol.Location {
+	return d.Document.LocationFromNode(d.Name)
+}
+
+// ProjectContext is the project a ProjectLinter checks
+type ProjectContext struct {
+	// Project is the project evergreen loaded with all includes merged
+	Project *model.Project
+	// Documents are the documents that could be parsed, in the order
+	// evergreen merges them
+	Documents   []*project.Document
+	definitions map[EntityKind]map[string][]Definition
+	names       map[EntityKind][]string
+}
+
+func newProjectContext(workspace *project.Project) *ProjectContext {
+	p := &ProjectContext{
+		Project:     workspace.Data,
+		Documents:   []*project.Document{},
+		definitions: make(map[EntityKind]map[string][]Definition),
+		names:       make(map[EntityKind][]string),
+	}
+	for _, d := range workspace.Documents() {
+		if d.RootNode() != nil {
+			p.Documents = append(p.Documents, d)
+		}
+	}
+	for _, d := range p.Documents {
+		for kind, field := range entityFields {
+			list := topLevelField(d, field)
+			if list == nil {
+				continue
+			}
+			for _, entry := range SequenceEntries(list) {
+				if name, ok := MappingField(entry, "name").(*ast.StringNode); ok {
+					p.addDefinition(kind, name.Value, Definition{Document: d, Name: name, Entry: UnwrapAnchor(entry)})
+				}
+			}
+		}
+		if functions, ok := topLevelField(d, "functions").(*ast.MappingNode); ok {
+			for _, v := range functions.Values {
+				p.addDefinition(EntityFunction, v.Key.GetToken().Value, Definition{Document: d, Name: v.Key, Entry: v.Value})
+			}
+		}
+	}
+	return p
+}
+
+func (p *ProjectContext) addDefinition(kind EntityKind, name string, def Definition) {
+	if p.definitions[kind] == nil {
+		p.definitions[kind] = make(map[string][]Definition)
+	}
+	if _, ok := p.definitions[kind][name]; !ok {
+		p.names[kind] = append(p.names[kind], name)
+	}
+	p.definitions[kind][name] = append(p.definitions[kind][name], def)
+}
+
+// Names returns the names of every declared entity of a kind in the order
+// they are first declared
+func (p *ProjectContext) Names(kind EntityKind) []string {
+	return p.names[kind]
+}
+
+// Definitions returns every declaration of an entity in merge order. There is
+// more than one when the entity is declared in several documents.
+func (p *ProjectContext) Definitions(kind EntityKind, name string) []Definition {
+	return p.definitions[kind][name]
+}
+
+// Locate maps an entity of the loaded project back to where it is declared
+func (p *ProjectContext) Locate(entity any) []Definition {
+	switch e := entity.(type) {
+	case model.ProjectTask:
+		return p.Definitions(EntityTask, e.Name)
+	case *model.ProjectTask:
+		return p.Definitions(EntityTask, e.Name)
+	case model.TaskGroup:
+		return p.Definitions(EntityTaskGroup, e.Name)
+	case *model.TaskGroup:
+		return p.Definitions(EntityTaskGroup, e.Name)
+	case model.BuildVariant:
+		return p.Definitions(EntityBuildVariant, e.Name)
+	case *model.BuildVariant:
+		return p.Definitions(EntityBuildVariant, e.Name)
+	}
+	return nil
+}
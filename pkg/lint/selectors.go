@@ -0,0 +1,135 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/util"
+)
+
+type UnmatchedSelectorLinter struct {
+	executor *Executor
+}
+
+func (l *UnmatchedSelectorLinter) Register(executor *Executor) {
+	l.executor = executor
+}
+
+func (l *UnmatchedSelectorLinter) Enabled(_ config.Lint) bool {
+	return true
+}
+
+func (l *UnmatchedSelectorLinter) Check(node ast.Node) []protocol.Diagnostic {
+	diagnostics := []protocol.Diagnostic{}
+	if n, ok := node.(*ast.MappingValueNode); ok {
+		data := l.executor.workspace.Data
+		switch n.Key.GetToken().Value {
+		case "tasks":
+			switch {
+			case buildVariantTasksPath.MatchString(n.GetPath()):
+				items := append(taskItems(data.Tasks), taskGroupItems(data.TaskGroups)...)
+				for _, entry := range SequenceEntries(n.Value) {
+					diagnostics = append(diagnostics, l.checkSelector(EntryField(entry, "name"), items)...)
+				}
+			case taskGroupTasksPath.MatchString(n.GetPath()):
+				for _, entry := range SequenceEntries(n.Value) {
+					diagnostics = append(diagnostics, l.checkSelector(entry, taskItems(data.Tasks))...)
+				}
+			}
+
+		case "depends_on":
+			items := append(taskItems(data.Tasks), taskGroupItems(data.TaskGroups)...)
+			for _, entry := range SequenceEntries(n.Value) {
+				diagnostics = append(diagnostics, l.checkSelector(EntryField(entry, "name"), items)...)
+			}
+		}
+	}
+	return diagnostics
+}
+
+func (l *UnmatchedSelectorLinter) checkSelector(node ast.Node, items []selectable) []protocol.Diagnostic {
+	diagnostics := []protocol.Diagnostic{}
+	s, ok := node.(*ast.StringNode)
+	if !ok || !isTagSelector(s.Value) {
+		return diagnostics
+	}
+	matched := evalSelector(s.Value, items)
+	switch {
+	case len(matched) == 0:
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Severity: protocol.DiagnosticSeverityWarning,
+			Source:   "no-unmatched-selector",
+			Message:  fmt.Sprintf("selector %q does not match any task", s.Value),
+			Range:    util.RangeFromNode(s, nil),
+		})
+	case !slices.ContainsFunc(matched, func(i selectable) bool { return !i.disabled }):
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Severity: protocol.DiagnosticSeverityWarning,
+			Source:   "no-unmatched-selector",
+			Message:  fmt.Sprintf("selector %q only matches disabled tasks", s.Value),
+			Range:    util.RangeFromNode(s, nil),
+		})
+	}
+	return diagnostics
+}
+
+// selectable is anything a task selector can match
+type selectable struct {
+	name     string
+	tags     []string
+	disabled bool
+}
+
+func taskItems(tasks []model.ProjectTask) []selectable {
+	items := make([]selectable, 0, len(tasks))
+	for _, t := range tasks {
+		items = append(items, selectable{name: t.Name, tags: t.Tags, disabled: t.Disable != nil && *t.Disable})
+	}
+	return items
+}
+
+func taskGroupItems(groups []model.TaskGroup) []selectable {
+	items := make([]selectable, 0, len(groups))
+	for _, tg := range groups {
+		items = append(items, selectable{name: tg.Name, tags: tg.Tags})
+	}
+	return items
+}
+
+// isTagSelector reports whether a selector does more than name a single task.
+// Plain names are left to the undefined linter.
+func isTagSelector(selector string) bool {
+	return slices.ContainsFunc(strings.Fields(selector), func(c string) bool {
+		return strings.HasPrefix(c, ".") || strings.HasPrefix(c, "!")
+	})
+}
+
+// evalSelector returns the items matching every criterion of a selector,
+// following the same rules evergreen uses when translating a project
+func evalSelector(selector string, items []selectable) []selectable {
+	matched := items
+	for _, criterion := range strings.Fields(selector) {
+		negated := strings.HasPrefix(criterion, "!")
+		criterion = strings.TrimPrefix(criterion, "!")
+		tagged := strings.HasPrefix(criterion, ".")
+		criterion = strings.TrimPrefix(criterion, ".")
+		if criterion == model.SelectAll {
+			continue
+		}
+		matched = slices.DeleteFunc(slices.Clone(matched), func(i selectable) bool {
+			var matches bool
+			if tagged {
+				matches = slices.Contains(i.tags, criterion)
+			} else {
+				matches = i.name == criterion
+			}
+			return matches == negated
+		})
+	}
+	return matched
+}
@@ -0,0 +1,41 @@
+package lint
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEvalSelector(t *testing.T) {
+	items := []selectable{
+		{name: "compile", tags: []string{"build"}},
+		{name: "unit", tags: []string{"test", "fast"}},
+		{name: "e2e", tags: []string{"test", "slow"}},
+		{name: "lint", tags: []string{"fast"}, disabled: true},
+	}
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{selector: "unit", want: []string{"unit"}},
+		{selector: "missing", want: []string{}},
+		{selector: ".test", want: []string{"unit", "e2e"}},
+		{selector: "!.test", want: []string{"compile", "lint"}},
+		{selector: "*", want: []string{"compile", "unit", "e2e", "lint"}},
+		{selector: ".test .fast", want: []string{"unit"}},
+		{selector: ".test !.fast", want: []string{"e2e"}},
+		{selector: ".fast !lint", want: []string{"unit"}},
+		{selector: ".build .slow", want: []string{}},
+		{selector: ".missing", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got := []string{}
+			for _, i := range evalSelector(tt.selector, items) {
+				got = append(got, i.name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("evalSelector(%q) = %q, want %q", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package lint
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/goccy/go-yaml/lexer"
+	"github.com/goccy/go-yaml/token"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// suppressionPattern matches comments such as
+// `# evergreen-lsp-disable-next-line enforce-tags, no-undefined`
+var suppressionPattern = regexp.MustCompile(`^\s*evergreen-lsp-(disable-next-line|disable-file|disable|enable)\b(.*)$`)
+
+// suppression hides diagnostics of the given rules, or every rule when there
+// are none, on an inclusive range of 0-based lines
+type suppression struct {
+	start uint32
+	end   uint32
+	rules []string
+}
+
+func (s suppression) matches(d protocol.Diagnostic) bool {
+	line := d.Range.Start.Line
+	return line >= s.start && line <= s.end && (len(s.rules) == 0 || slices.Contains(s.rules, d.Source))
+}
+
+// findSuppressions reads the suppression comments of a document. A disable
+// comment lasts until an enable comment naming its rules, or one without any
+// rules, and otherwise until the end of the file.
+func findSuppressions(doc *project.Document) []suppression {
+	suppressions := []suppression{}
+	open := []suppression{}
+	closeOpen := func(line uint32, rules []string) {
+		stillOpen := []suppression{}
+		for _, s := range open {
+			switch {
+			case len(rules) == 0:
+			case len(s.rules) == 0 || !slices.ContainsFunc(s.rules, func(r string) bool { return slices.Contains(rules, r) }):
+				// Enabling single rules leaves disables of every rule and of other
+				// rules in place
+				stillOpen = append(stillOpen, s)
+				continue
+			default:
+				remaining := slices.DeleteFunc(slices.Clone(s.rules), func(r string) bool { return slices.Contains(rules, r) })
+				if len(remaining) > 0 {
+					stillOpen = append(stillOpen, suppression{start: line, rules: remaining})
+				}
+			}
+			s.end = line
+			suppressions = append(suppressions, s)
+		}
+		open = stillOpen
+	}
+
+	for _, t := range lexer.Tokenize(doc.Text) {
+		if t.Type != token.CommentType {
+			continue
+		}
+		match := suppressionPattern.FindStringSubmatch(t.Value)
+		if match == nil {
+			continue
+		}
+		// Lexer positions are 1-based
+		line := uint32(t.Position.Line - 1) //nolint:gosec
+		rules := parseRuleList(match[2])
+		switch match[1] {
+		case "disable-next-line":
+			suppressions = append(suppressions, suppression{start: line + 1, end: line + 1, rules: rules})
+		case "disable-file":
+			suppressions = append(suppressions, suppression{start: 0, end: math.MaxUint32, rules: rules})
+		case "disable":
+			open = append(open, suppression{start: line, rules: rules})
+		case "enable":
+			closeOpen(line, rules)
+		}
+	}
+	closeOpen(math.MaxUint32, nil)
+	return suppressions
+}
+
+func parseRuleList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// filterSuppressed removes the diagnostics a document's comments suppress
+func filterSuppressed(doc *project.Document, diagnostics []protocol.Diagnostic) []protocol.Diagnostic {
+	suppressions := findSuppressions(doc)
+	if len(suppressions) == 0 {
+		return diagnostics
+	}
+	return slices.DeleteFunc(diagnostics, func(d protocol.Diagnostic) bool {
+		return slices.ContainsFunc(suppressions, func(s suppression) bool {
+			return s.matches(d)
+		})
+	})
+}
@@ -14,14 +14,19 @@ import (
 
 type EnforceTagsLinter struct {
 	executor *Executor
+	options  struct {
+		// Allow lists task names that may be referenced directly
+		Allow []string `yaml:"allow"`
+	}
 }
 
 func (l *EnforceTagsLinter) Register(executor *Executor) {
 	l.executor = executor
+	executor.ruleOptions("enforce-tags", &l.options)
 }
 
-func (l *EnforceTagsLinter) Enabled(settings config.Lint) bool {
-	return settings.EnforceTags
+func (l *EnforceTagsLinter) Enabled(_ config.Lint) bool {
+	return true
 }
 
 func (l *EnforceTagsLinter) Check(node ast.Node) []protocol.Diagnostic {
@@ -34,7 +39,8 @@ func (l *EnforceTagsLinter) Check(node ast.Node) []protocol.Diagnostic {
 			for _, name := range nameNodes {
 				names := strings.Split(name.GetToken().Value, " ")
 				usesDirectName := slices.ContainsFunc(names, func(n string) bool {
-					return n != "*" && !strings.HasPrefix(n, "!.") && !strings.HasPrefix(n, ".")
+					return n != "*" && !strings.HasPrefix(n, "!.") && !strings.HasPrefix(n, ".") &&
+						!slices.Contains(l.options.Allow, strings.TrimPrefix(n, "!"))
 				})
 				if usesDirectName {
 					diagnostics = append(diagnostics, protocol.Diagnostic{
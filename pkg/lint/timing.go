@@ -0,0 +1,277 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/evergreen-ci/evergreen/agent/globals"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/util"
+)
+
+// timeoutFields are the fields evergreen reads as a number of seconds
+var timeoutFields = []string{
+	"exec_timeout_secs",
+	"timeout_secs",
+	"pre_timeout_secs",
+	"post_timeout_secs",
+	"callback_timeout_secs",
+	"setup_group_timeout_secs",
+	"teardown_group_timeout_secs",
+	"setup_task_timeout_secs",
+	"teardown_task_timeout_secs",
+}
+
+var (
+	// scheduledPath matches the places batchtime and cron can be set
+	scheduledPath = regexp.MustCompile(`^\$\.buildvariants\[\d+\](\.tasks\[\d+\])?$`)
+	taskPath      = regexp.MustCompile(`^\$\.tasks\[\d+\]$`)
+)
+
+// TimingLinter checks timeouts and activation schedules for values evergreen
+// accepts but that do not do what was intended
+type TimingLinter struct {
+	executor *Executor
+	// taskTimeouts is the longest exec timeout each task can run with
+	taskTimeouts map[string]int
+	// functionTimeouts is the longest exec timeout of the tasks calling each
+	// function, for functions that are only called by tasks
+	functionTimeouts map[string]int
+	options          struct {
+		// MaxTimeoutSecs is the longest timeout that is not reported as a mistake
+		MaxTimeoutSecs int `yaml:"max_timeout_secs"`
+	}
+}
+
+func (l *TimingLinter) Register(executor *Executor) {
+	l.executor = executor
+	l.options.MaxTimeoutSecs = config.DefaultMaxTimeoutSecs
+	executor.ruleOptions("timing", &l.options)
+	l.taskTimeouts = l.taskExecTimeouts()
+	l.functionTimeouts = l.functionExecTimeouts()
+}
+
+func (l *TimingLinter) Enabled(_ config.Lint) bool {
+	return true
+}
+
+func (l *TimingLinter) Check(node ast.Node) []protocol.Diagnostic {
+	diagnostics := []protocol.Diagnostic{}
+	switch n := node.(type) {
+	case *ast.MappingValueNode:
+		key := n.Key.GetToken().Value
+		switch {
+		case slices.Contains(timeoutFields, key):
+			diagnostics = append(diagnostics, l.checkTimeout(key, n.Value)...)
+		case key == "batchtime":
+			if v, ok := intValue(n.Value); ok && v < 0 {
+				diagnostics = append(diagnostics, protocol.Diagnostic{
+					Severity: protocol.DiagnosticSeverityError,
+					Source:   "timing",
+					Message:  "batchtime must not be negative",
+					Range:    util.RangeFromNode(n.Value, nil),
+				})
+			}
+		case key == "cron" && scheduledPath.MatchString(parentPath(n.GetPath())):
+			s, ok := n.Value.(*ast.StringNode)
+			if !ok {
+				break
+			}
+			if _, err := model.GetNextCronTime(time.Now(), s.Value); err != nil {
+				diagnostics = append(diagnostics, protocol.Diagnostic{
+					Severity: protocol.DiagnosticSeverityError,
+					Source:   "timing",
+					Message:  fmt.Sprintf("cron %q has invalid syntax: %s", s.Value, err),
+					Range:    util.RangeFromNode(s, nil),
+				})
+			}
+		}
+
+	case *ast.MappingNode:
+		switch {
+		case scheduledPath.MatchString(n.GetPath()):
+			if cron := mappingEntry(n, "cron"); cron != nil && mappingEntry(n, "batchtime") != nil {
+				diagnostics = append(diagnostics, protocol.Diagnostic{
+					Severity: protocol.DiagnosticSeverityError,
+					Source:   "timing",
+					Message:  "batchtime and cron cannot both be set, evergreen rejects the variant",
+					Range:    util.RangeFromNode(cron, nil),
+				})
+			}
+		case taskPath.MatchString(n.GetPath()):
+			execTimeout := l.projectExecTimeout()
+			if name, ok := MappingField(n, "name").(*ast.StringNode); ok {
+				if v, ok := l.taskTimeouts[name.Value]; ok {
+					execTimeout = v
+				}
+			}
+			for _, c := range SequenceEntries(MappingField(n, "commands")) {
+				diagnostics = append(diagnostics, l.checkTimeoutUpdate(c, execTimeout)...)
+			}
+		case n.GetPath() == "$.functions":
+			// Commands in pre, post and task groups run with whichever task is
+			// running, so only functions have a known set of tasks
+			for _, f := range n.Values {
+				execTimeout, ok := l.functionTimeouts[f.Key.GetToken().Value]
+				if !ok {
+					continue
+				}
+				for _, c := range SequenceEntries(f.Value) {
+					diagnostics = append(diagnostics, l.checkTimeoutUpdate(c, execTimeout)...)
+				}
+			}
+		}
+	}
+	return diagnostics
+}
+
+func (l *TimingLinter) checkTimeout(field string, node ast.Node) []protocol.Diagnostic {
+	diagnostics := []protocol.Diagnostic{}
+	v, ok := intValue(node)
+	if !ok {
+		return diagnostics
+	}
+	limit := l.options.MaxTimeoutSecs
+	switch {
+	case v < 0:
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Severity: protocol.DiagnosticSeverityError,
+			Source:   "timing",
+			Message:  fmt.Sprintf("%s must not be negative", field),
+			Range:    util.RangeFromNode(node, nil),
+		})
+	case limit > 0 && v > limit:
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Severity: protocol.DiagnosticSeverityWarning,
+			Source:   "timing",
+			Message:  fmt.Sprintf("%s is %s, which is longer than the limit of %s", field, secondsString(v), secondsString(limit)),
+			Range:    util.RangeFromNode(node, nil),
+		})
+	}
+	return diagnostics
+}
+
+// checkTimeoutUpdate reports a timeout.update command whose idle timeout is
+// longer than the exec timeout the task runs with, since the exec timeout
+// always fires first
+func (l *TimingLinter) checkTimeoutUpdate(node ast.Node, execTimeout int) []protocol.Diagnostic {
+	diagnostics := []protocol.Diagnostic{}
+	if c := MappingField(node, "command"); c == nil || c.GetToken().Value != "timeout.update" {
+		return diagnostics
+	}
+	params := MappingField(node, "params")
+	idleNode := MappingField(params, "timeout_secs")
+	idle, ok := intValue(idleNode)
+	if !ok {
+		return diagnostics
+	}
+	if v, ok := intValue(MappingField(params, "exec_timeout_secs")); ok && v > 0 {
+		execTimeout = v
+	}
+	if idle > execTimeout {
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Severity: protocol.DiagnosticSeverityWarning,
+			Source:   "timing",
+			Message: fmt.Sprintf("timeout.update sets timeout_secs to %s but the exec timeout is only %s, so the task is killed before it can time out for being idle",
+				secondsString(idle), secondsString(execTimeout)),
+			Range: util.RangeFromNode(idleNode, nil),
+		})
+	}
+	return diagnostics
+}
+
+// taskExecTimeouts returns the longest exec timeout each task can run with,
+// taking the exec_timeout_secs set for it by the variants that list it into
+// account
+func (l *TimingLinter) taskExecTimeouts() map[string]int {
+	timeouts := make(map[string]int)
+	for _, t := range l.executor.workspace.Data.Tasks {
+		timeouts[t.Name] = l.projectExecTimeout()
+		if t.ExecTimeoutSecs > 0 {
+			timeouts[t.Name] = t.ExecTimeoutSecs
+		}
+	}
+	for _, d := range l.executor.workspace.Documents() {
+		for _, bv := range SequenceEntries(topLevelField(d, "buildvariants")) {
+			for _, entry := range SequenceEntries(MappingField(bv, "tasks")) {
+				name, ok := EntryField(entry, "name").(*ast.StringNode)
+				if !ok {
+					continue
+				}
+				if current, ok := timeouts[name.Value]; ok {
+					if v, ok := intValue(MappingField(entry, "exec_timeout_secs")); ok && v > current {
+						timeouts[name.Value] = v
+					}
+				}
+			}
+		}
+	}
+	return timeouts
+}
+
+// functionExecTimeouts returns the longest exec timeout of the tasks calling
+// each function. Functions called anywhere else are left out.
+func (l *TimingLinter) functionExecTimeouts() map[string]int {
+	data := l.executor.workspace.Data
+	timeouts := make(map[string]int)
+	for _, t := range data.Tasks {
+		for _, c := range t.Commands {
+			if c.Function != "" {
+				timeouts[c.Function] = max(timeouts[c.Function], l.taskTimeouts[t.Name])
+			}
+		}
+	}
+	sets := []*model.YAMLCommandSet{data.Pre, data.Post, data.Timeout}
+	for _, f := range data.Functions {
+		sets = append(sets, f)
+	}
+	for _, tg := range data.TaskGroups {
+		sets = append(sets, tg.SetupGroup, tg.TeardownGroup, tg.SetupTask, tg.TeardownTask, tg.Timeout)
+	}
+	for _, set := range sets {
+		if set == nil {
+			continue
+		}
+		for _, c := range set.List() {
+			delete(timeouts, c.Function)
+		}
+	}
+	return timeouts
+}
+
+func (l *TimingLinter) projectExecTimeout() int {
+	if secs := l.executor.workspace.Data.ExecTimeoutSecs; secs > 0 {
+		return secs
+	}
+	return int(globals.DefaultExecTimeout.Seconds())
+}
+
+// intValue returns the value of an integer literal. Anything else, such as an
+// expansion, is only known at runtime.
+func intValue(node ast.Node) (int, bool) {
+	n, ok := UnwrapAnchor(node).(*ast.IntegerNode)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.Atoi(n.GetToken().Value)
+	return v, err == nil
+}
+
+// parentPath strips the last element of a YAML path
+func parentPath(path string) string {
+	if i := strings.LastIndex(path, "."); i > 0 {
+		return path[:i]
+	}
+	return path
+}
+
+func secondsString(secs int) string {
+	return (time.Duration(secs) * time.Second).String()
+}
@@ -2,7 +2,10 @@ package lint
 
 import (
 	"fmt"
+	"maps"
+	"regexp"
 	"slices"
+	"strings"
 
 	"github.com/a-h/templ/lsp/protocol"
 	"github.com/evergreen-ci/evergreen/agent/command"
@@ -11,6 +14,11 @@ import (
 	"github.com/lavigneer/evergreen-lsp/pkg/util"
 )
 
+var (
+	buildVariantTasksPath = regexp.MustCompile(`^\$\.buildvariants\[\d+\]\.tasks$`)
+	taskGroupTasksPath    = regexp.MustCompile(`^\$\.task_groups\[\d+\]\.tasks$`)
+)
+
 type UndefinedLinter struct {
 	executor *Executor
 }
@@ -31,12 +39,8 @@ func (l *UndefinedLinter) Check(node ast.Node) []protocol.Diagnostic {
 			nodeStr := n.Value.GetToken().Value
 			_, ok := l.executor.workspace.Data.Functions[nodeStr]
 			if !ok {
-				diagnostics = append(diagnostics, protocol.Diagnostic{
-					Severity: protocol.DiagnosticSeverityError,
-					Source:   "no-undefined",
-					Message:  fmt.Sprintf("function %q is not defined", nodeStr),
-					Range:    util.RangeFromNode(n.Value, nil),
-				})
+				functions := slices.Sorted(maps.Keys(l.executor.workspace.Data.Functions))
+				diagnostics = append(diagnostics, undefinedDiagnostic(n.Value, "function", nodeStr, nodeStr, functions))
 			}
 
 		case "command":
@@ -44,15 +48,115 @@ func (l *UndefinedLinter) Check(node ast.Node) []protocol.Diagnostic {
 			commands := command.RegisteredCommandNames()
 			ok := slices.Contains(commands, nodeStr)
 			if !ok {
-				diagnostics = append(diagnostics, protocol.Diagnostic{
-					Severity: protocol.DiagnosticSeverityError,
-					Source:   "no-undefined",
-					Message:  fmt.Sprintf("command %q is not defined", nodeStr),
-					Range:    util.RangeFromNode(n.Value, nil),
-				})
+				diagnostics = append(diagnostics, undefinedDiagnostic(n.Value, "command", nodeStr, nodeStr, commands))
 			}
 
+		case "tasks":
+			switch {
+			case buildVariantTasksPath.MatchString(n.GetPath()):
+				// Build variants can list both tasks and task groups
+				for _, entry := range SequenceEntries(n.Value) {
+					diagnostics = append(diagnostics, l.checkNames(EntryField(entry, "name"), "task or task group", l.isTaskOrGroup, l.taskAndGroupNames())...)
+				}
+			case taskGroupTasksPath.MatchString(n.GetPath()):
+				for _, entry := range SequenceEntries(n.Value) {
+					diagnostics = append(diagnostics, l.checkNames(entry, "task", l.isTask, l.taskNames())...)
+				}
+			}
+
+		case "depends_on":
+			for _, entry := range SequenceEntries(n.Value) {
+				diagnostics = append(diagnostics, l.checkNames(EntryField(entry, "name"), "task", l.isTask, l.taskNames())...)
+				diagnostics = append(diagnostics, l.checkNames(MappingField(entry, "variant"), "build variant", l.isVariant, l.variantNames())...)
+			}
+
+		case "execution_tasks":
+			for _, entry := range SequenceEntries(n.Value) {
+				diagnostics = append(diagnostics, l.checkNames(entry, "task", l.isTask, l.taskNames())...)
+			}
+		}
+	}
+	return diagnostics
+}
+
+// checkNames reports every plain name within a selector that is not defined.
+// Tag criteria are left to the tag linters since they do not name a single entity.
+func (l *UndefinedLinter) checkNames(node ast.Node, kind string, defined func(string) bool, candidates []string) []protocol.Diagnostic {
+	diagnostics := []protocol.Diagnostic{}
+	s, ok := node.(*ast.StringNode)
+	if !ok {
+		return diagnostics
+	}
+	for _, criterion := range strings.Fields(s.Value) {
+		name := strings.TrimPrefix(criterion, "!")
+		if name == "*" || strings.HasPrefix(name, ".") || defined(name) {
+			continue
 		}
+		diagnostics = append(diagnostics, undefinedDiagnostic(s, kind, name, criterion, candidates))
 	}
 	return diagnostics
 }
+
+// undefinedDiagnostic reports an undefined name and suggests the closest
+// defined one. The suggestion is offered as a fix when the criterion naming it
+// makes up the whole node.
+func undefinedDiagnostic(node ast.Node, kind string, name string, criterion string, candidates []string) protocol.Diagnostic {
+	d := protocol.Diagnostic{
+		Severity: protocol.DiagnosticSeverityError,
+		Source:   "no-undefined",
+		Message:  fmt.Sprintf("%s %q is not defined", kind, name),
+		Range:    util.RangeFromNode(node, nil),
+	}
+	suggestion, ok := util.ClosestMatch(name, candidates)
+	if !ok {
+		return d
+	}
+	d.Message += fmt.Sprintf(", did you mean %q?", suggestion)
+	if strings.TrimSpace(node.GetToken().Value) != criterion {
+		return d
+	}
+	replacement := strings.Replace(criterion, name, suggestion, 1)
+	if edit, ok := replaceNodeEdit(node, replacement); ok {
+		d.Data = &Fix{
+			Title: fmt.Sprintf("Replace with %q", suggestion),
+			Edits: []protocol.TextEdit{edit},
+		}
+	}
+	return d
+}
+
+func (l *UndefinedLinter) isTask(name string) bool {
+	return l.executor.workspace.Data.FindProjectTask(name) != nil
+}
+
+func (l *UndefinedLinter) isTaskOrGroup(name string) bool {
+	return l.isTask(name) || l.executor.workspace.Data.FindTaskGroup(name) != nil
+}
+
+func (l *UndefinedLinter) taskNames() []string {
+	names := []string{}
+	for _, t := range l.executor.workspace.Data.Tasks {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func (l *UndefinedLinter) taskAndGroupNames() []string {
+	names := l.taskNames()
+	for _, tg := range l.executor.workspace.Data.TaskGroups {
+		names = append(names, tg.Name)
+	}
+	return names
+}
+
+func (l *UndefinedLinter) variantNames() []string {
+	names := []string{}
+	for _, bv := range l.executor.workspace.Data.BuildVariants {
+		names = append(names, bv.Name)
+	}
+	return names
+}
+
+func (l *UndefinedLinter) isVariant(name string) bool {
+	return l.executor.workspace.Data.FindBuildVariant(name) != nil
+}
@@ -0,0 +1,49 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/lavigneer/evergreen-lsp/pkg/config"
+)
+
+type UnusedFunctionLinter struct {
+	executor *Executor
+}
+
+func (l *UnusedFunctionLinter) Register(executor *Executor) {
+	l.executor = executor
+}
+
+func (l *UnusedFunctionLinter) Enabled(_ config.Lint) bool {
+	return true
+}
+
+func (l *UnusedFunctionLinter) CheckProject(project *ProjectContext) ExecutorDiagnostics {
+	diagnostics := make(ExecutorDiagnostics)
+	for _, name := range project.Names(EntityFunction) {
+		if isCalled(project, name) {
+			continue
+		}
+		for _, def := range project.Definitions(EntityFunction, name) {
+			diagnostics[def.Document] = append(diagnostics[def.Document], protocol.Diagnostic{
+				Severity: protocol.DiagnosticSeverityWarning,
+				Source:   "no-unused-function",
+				Message:  fmt.Sprintf("function %q is never called", name),
+				Range:    def.Range(),
+			})
+		}
+	}
+	return diagnostics
+}
+
+// isCalled checks every document in the project since functions are commonly
+// defined in one include file and called from another
+func isCalled(project *ProjectContext, name string) bool {
+	for _, d := range project.Documents {
+		if len(d.References[name]) > 0 {
+			return true
+		}
+	}
+	return false
+}
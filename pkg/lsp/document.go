@@ -3,10 +3,12 @@ package lsp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 
 	"github.com/a-h/templ/lsp/protocol"
 	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
 	"github.com/sourcegraph/jsonrpc2"
 )
 
@@ -29,8 +31,7 @@ func (h *Handler) handleTextDocumentDidOpen(ctx context.Context, req *jsonrpc2.R
 	}
 	h.openDocuments[params.TextDocument.URI] = struct{}{}
 	if res, ok := h.config.FindProjDoc(params.TextDocument.URI); ok {
-		err := h.notifyDiagnostics(ctx, res.Document.URI)
-		return err
+		return h.notifyDiagnostics(ctx, res.Project)
 	}
 	return nil
 }
@@ -44,22 +45,31 @@ func (h *Handler) handleTextDocumentDidClose(_ context.Context, req *jsonrpc2.Re
 	return nil
 }
 
-func (h *Handler) notifyDiagnostics(ctx context.Context, docURI protocol.DocumentURI) error {
-	if res, ok := h.config.FindProjDoc(docURI); ok {
-		lintExecutor := lint.New(res.Project, h.config.Lint)
-		diagnostics, err := lintExecutor.LintDocument(res.Document.URI)
-		if err != nil {
-			return err
+// notifyDiagnostics publishes the diagnostics of every open document of a
+// project. Project linters report problems across documents, so linting one
+// document can change the diagnostics of the others.
+func (h *Handler) notifyDiagnostics(ctx context.Context, proj *project.Project) error {
+	open := []protocol.DocumentURI{}
+	for uri := range proj.TextDocuments {
+		if _, ok := h.openDocuments[uri]; ok {
+			open = append(open, uri)
 		}
-		err = h.conn.Notify(ctx, protocol.MethodTextDocumentPublishDiagnostics, protocol.PublishDiagnosticsParams{
-			URI: res.Document.URI,
+	}
+	lintExecutor := lint.New(proj, h.config.Lint)
+	var errs []error
+	for doc, diagnostics := range lintExecutor.LintDocuments(open) {
+		diagnostics = h.baseline.Apply(proj.Root(), doc, diagnostics, h.config.Lint.ShowBaselined)
+		err := h.conn.Notify(ctx, protocol.MethodTextDocumentPublishDiagnostics, protocol.PublishDiagnosticsParams{
+			URI: doc.URI,
 			//nolint:gosec
-			Version:     uint32(res.Document.Version),
+			Version:     uint32(doc.Version),
 			Diagnostics: diagnostics,
 		})
-		return err
+		if err != nil {
+			errs = append(errs, err)
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 func (h *Handler) handleTextDocumentDidSave(ctx context.Context, req *jsonrpc2.Request) error {
@@ -73,13 +83,8 @@ func (h *Handler) handleTextDocumentDidSave(ctx context.Context, req *jsonrpc2.R
 		if err != nil {
 			return err
 		}
-		for _, doc := range res.Project.TextDocuments {
-			if _, ok := h.openDocuments[doc.URI]; ok {
-				err := h.notifyDiagnostics(ctx, doc.URI)
-				if err != nil {
-					slog.Error("Could not notify document of diagnostics", "error", err)
-				}
-			}
+		if err := h.notifyDiagnostics(ctx, res.Project); err != nil {
+			slog.Error("Could not notify documents of diagnostics", "error", err)
 		}
 	}
 	return nil
@@ -11,6 +11,7 @@ import (
 	"github.com/a-h/templ/lsp/protocol"
 	"github.com/a-h/templ/lsp/uri"
 	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
 	"github.com/sourcegraph/jsonrpc2"
 )
 
@@ -18,6 +19,7 @@ type Handler struct {
 	conn              *jsonrpc2.Conn
 	request           chan protocol.DocumentURI
 	config            *config.Config
+	baseline          *lint.Baseline
 	openDocuments     map[protocol.DocumentURI]struct{}
 	shutdownRequested bool
 }
@@ -66,6 +68,10 @@ func (h *Handler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2
 		return h.handleTextDocumentHover(ctx, req)
 	case protocol.MethodTextDocumentReferences:
 		return h.handleTextDocumentReferences(ctx, req)
+	case protocol.MethodTextDocumentPrepareRename:
+		return h.handleTextDocumentPrepareRename(ctx, req)
+	case protocol.MethodTextDocumentRename:
+		return h.handleTextDocumentRename(ctx, req)
 	}
 	return nil, &jsonrpc2.Error{
 		Code:    jsonrpc2.CodeMethodNotFound,
@@ -94,9 +100,22 @@ func (h *Handler) handleInitialize(ctx context.Context, conn *jsonrpc2.Conn, req
 	}
 	h.config = cfg
 	h.conn = conn
+	if path := cfg.Lint.BaselinePath(workspaceRoot); path != "" {
+		baseline, err := lint.LoadBaseline(path)
+		if err != nil {
+			slog.Warn("Could not load baseline", "path", path, "error", err)
+		}
+		h.baseline = baseline
+	}
 
 	slog.Debug("Initialized", "workspaceFolders", params.WorkspaceFolders)
 
+	// Rename options are only allowed when the client can prepare renames
+	var renameProvider any = true
+	if td := params.Capabilities.TextDocument; td != nil && td.Rename != nil && td.Rename.PrepareSupport {
+		renameProvider = &protocol.RenameOptions{PrepareProvider: true}
+	}
+
 	return protocol.InitializeResult{
 		Capabilities: protocol.ServerCapabilities{
 			TextDocumentSync: protocol.TextDocumentSyncOptions{
@@ -110,6 +129,7 @@ func (h *Handler) handleInitialize(ctx context.Context, conn *jsonrpc2.Conn, req
 			DefinitionProvider: &protocol.DefinitionOptions{},
 			HoverProvider:      &protocol.HoverOptions{},
 			ReferencesProvider: &protocol.ReferenceOptions{},
+			RenameProvider:     renameProvider,
 		},
 	}, nil
 }
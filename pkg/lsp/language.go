@@ -10,6 +10,8 @@ import (
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/config"
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
 	"github.com/sourcegraph/jsonrpc2"
 )
 
@@ -110,12 +112,30 @@ func (h *Handler) handleTextDocumentHover(ctx context.Context, req *jsonrpc2.Req
 		}
 
 		nodeStr := node.GetToken().Value
+		if lint.IsDistroPath(node.GetPath()) {
+			if distro, ok := h.config.Lint.Distros.Find(nodeStr); ok {
+				return distroHover(ctx, distro)
+			}
+		}
 		def := res.Project.Hover(ctx, nodeStr)
 		return def, nil
 	}
 	return nil, ErrDocumentNotFound
 }
 
+func distroHover(ctx context.Context, distro *config.Distro) (*protocol.Hover, error) {
+	detail, err := yaml.MarshalContext(ctx, distro)
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  protocol.PlainText,
+			Value: string(detail),
+		},
+	}, nil
+}
+
 func (h *Handler) handleTextDocumentReferences(ctx context.Context, req *jsonrpc2.Request) (any, error) {
 	var params protocol.ReferenceParams
 	if err := json.Unmarshal(*req.Params, &params); err != nil {
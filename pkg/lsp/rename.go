@@ -0,0 +1,50 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// prepareRenameResult is the range of the name to rename along with its
+// current value, which protocol.Range alone does not carry
+type prepareRenameResult struct {
+	Range       protocol.Range `json:"range"`
+	Placeholder string         `json:"placeholder"`
+}
+
+func (h *Handler) handleTextDocumentPrepareRename(ctx context.Context, req *jsonrpc2.Request) (any, error) {
+	var params protocol.PrepareRenameParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+	if res, ok := h.config.FindProjDoc(params.TextDocument.URI); ok {
+		occurrence, err := symbolAt(res.Project, res.Document, params.Position)
+		if err != nil {
+			return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: err.Error()}
+		}
+		return prepareRenameResult{Range: occurrence.Range, Placeholder: occurrence.Symbol.Name}, nil
+	}
+	return nil, ErrDocumentNotFound
+}
+
+func (h *Handler) handleTextDocumentRename(ctx context.Context, req *jsonrpc2.Request) (any, error) {
+	var params protocol.RenameParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, err
+	}
+	if res, ok := h.config.FindProjDoc(params.TextDocument.URI); ok {
+		occurrence, err := symbolAt(res.Project, res.Document, params.Position)
+		if err != nil {
+			return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: err.Error()}
+		}
+		changes, err := renameSymbol(res.Project, occurrence.Symbol, params.NewName)
+		if err != nil {
+			return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: err.Error()}
+		}
+		return protocol.WorkspaceEdit{Changes: changes}, nil
+	}
+	return nil, ErrDocumentNotFound
+}
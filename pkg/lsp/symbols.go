@@ -0,0 +1,318 @@
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+const (
+	// entityTag is a tag of tasks and task groups
+	entityTag lint.EntityKind = "tag"
+	// entityBuildVariantTag is a tag of build variants, which variant
+	// selectors match
+	entityBuildVariantTag lint.EntityKind = "build variant tag"
+)
+
+var (
+	taskNamePath          = regexp.MustCompile(`^\$\.tasks\[\d+\]\.name$`)
+	taskGroupNamePath     = regexp.MustCompile(`^\$\.task_groups\[\d+\]\.name$`)
+	buildVariantNamePath  = regexp.MustCompile(`^\$\.buildvariants\[\d+\]\.name$`)
+	taskTagsPath          = regexp.MustCompile(`^\$\.(tasks|task_groups)\[\d+\]\.tags$`)
+	buildVariantTagsPath  = regexp.MustCompile(`^\$\.buildvariants\[\d+\]\.tags$`)
+	buildVariantTasksPath = regexp.MustCompile(`^\$\.buildvariants\[\d+\]\.tasks$`)
+	taskGroupTasksPath    = regexp.MustCompile(`^\$\.task_groups\[\d+\]\.tasks$`)
+	// symbolName is what names are restricted to when renaming, so they can be
+	// written without quotes and are not mistaken for selector criteria
+	symbolName = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.\-/+=@]*$`)
+	// functionName also allows spaces, since functions are not selected
+	functionName = regexp.MustCompile(`^[A-Za-z0-9_](?:[A-Za-z0-9_.\-/+=@ ]*[A-Za-z0-9_.\-/+=@])?$`)
+)
+
+// symbol is a named entity that can be renamed
+type symbol struct {
+	Kind lint.EntityKind
+	Name string
+}
+
+// occurrence is a place a symbol is defined or referenced
+type occurrence struct {
+	Symbol   symbol
+	Document *project.Document
+	// Range covers only the name, which can be part of a larger selector
+	Range      protocol.Range
+	Definition bool
+}
+
+// errNoSymbol is returned when there is nothing to rename at a position
+var errNoSymbol = errors.New("no function, task, task group, build variant or tag at this position")
+
+// symbolAt returns the occurrence of a symbol at a position in a document. The
+// kind of a plain name in a task list is resolved from the definitions, since
+// task lists can name both tasks and task groups.
+func symbolAt(workspace *project.Project, doc *project.Document, position protocol.Position) (occurrence, error) {
+	for _, o := range documentOccurrences(doc) {
+		if !rangeContains(o.Range, position) {
+			continue
+		}
+		if o.Symbol.Kind == lint.EntityTask && !o.Definition && !isDefined(workspace, o.Symbol) {
+			if group := (symbol{Kind: lint.EntityTaskGroup, Name: o.Symbol.Name}); isDefined(workspace, group) {
+				o.Symbol = group
+			}
+		}
+		if !isDefined(workspace, o.Symbol) {
+			return occurrence{}, fmt.Errorf("%s %q is not defined", o.Symbol.Kind, o.Symbol.Name)
+		}
+		return o, nil
+	}
+	return occurrence{}, errNoSymbol
+}
+
+// occurrences returns every definition of and reference to a symbol across
+// the project's documents
+func occurrences(workspace *project.Project, sym symbol) []occurrence {
+	found := []occurrence{}
+	for _, d := range workspace.Documents() {
+		for _, o := range documentOccurrences(d) {
+			if o.Symbol.Name == sym.Name && sameNamespace(o.Symbol.Kind, sym.Kind) {
+				found = append(found, o)
+			}
+		}
+	}
+	return found
+}
+
+// renameSymbol returns the edits that rename a symbol in every document of the
+// project. It fails when the new name is invalid or already taken, or when a
+// document cannot be parsed, since its positions would be out of date.
+func renameSymbol(workspace *project.Project, sym symbol, newName string) (map[protocol.DocumentURI][]protocol.TextEdit, error) {
+	valid := symbolName
+	if sym.Kind == lint.EntityFunction {
+		valid = functionName
+	}
+	if !valid.MatchString(newName) || newName == "*" {
+		return nil, fmt.Errorf("%q is not a valid %s name", newName, sym.Kind)
+	}
+	// Tags can be merged by renaming one to another, anything else would end
+	// up defined twice
+	taken := []lint.EntityKind{sym.Kind}
+	switch sym.Kind {
+	case entityTag, entityBuildVariantTag:
+		taken = nil
+	case lint.EntityTask, lint.EntityTaskGroup:
+		taken = []lint.EntityKind{lint.EntityTask, lint.EntityTaskGroup}
+	}
+	for _, kind := range taken {
+		if newName != sym.Name && isDefined(workspace, symbol{Kind: kind, Name: newName}) {
+			return nil, fmt.Errorf("%s %q is already defined", kind, newName)
+		}
+	}
+	for _, d := range workspace.Documents() {
+		if d.ParseError != nil {
+			return nil, fmt.Errorf("cannot rename while %s has errors: %w", displayPath(d), d.ParseError)
+		}
+	}
+
+	changes := make(map[protocol.DocumentURI][]protocol.TextEdit)
+	for _, o := range occurrences(workspace, sym) {
+		changes[o.Document.URI] = append(changes[o.Document.URI], protocol.TextEdit{Range: o.Range, NewText: newName})
+	}
+	return changes, nil
+}
+
+// sameNamespace reports whether two kinds of symbol share names. Task lists
+// and dependencies name tasks and task groups alike, which evergreen requires
+// to have distinct names.
+func sameNamespace(a lint.EntityKind, b lint.EntityKind) bool {
+	isTaskLike := func(k lint.EntityKind) bool { return k == lint.EntityTask || k == lint.EntityTaskGroup }
+	return a == b || (isTaskLike(a) && isTaskLike(b))
+}
+
+func isDefined(workspace *project.Project, sym symbol) bool {
+	switch sym.Kind {
+	case lint.EntityFunction:
+		_, ok := workspace.Data.Functions[sym.Name]
+		return ok
+	case lint.EntityTask:
+		return workspace.Data.FindProjectTask(sym.Name) != nil
+	case lint.EntityTaskGroup:
+		return workspace.Data.FindTaskGroup(sym.Name) != nil
+	case lint.EntityBuildVariant:
+		return workspace.Data.FindBuildVariant(sym.Name) != nil
+	}
+	// Tags are defined wherever they are listed
+	for _, d := range workspace.Documents() {
+		if slices.ContainsFunc(documentOccurrences(d), func(o occurrence) bool {
+			return o.Definition && o.Symbol == sym
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// documentOccurrences returns every occurrence of a symbol in a document
+func documentOccurrences(d *project.Document) []occurrence {
+	root := d.RootNode()
+	if root == nil {
+		return nil
+	}
+	c := &occurrenceCollector{document: d}
+	// Functions are already indexed when the document is parsed
+	for name, def := range d.Definitions {
+		c.addName(symbol{Kind: lint.EntityFunction, Name: name}, def.Node, true)
+	}
+	for name, refs := range d.References {
+		for _, ref := range refs {
+			c.addName(symbol{Kind: lint.EntityFunction, Name: name}, ref.Node, false)
+		}
+	}
+	ast.Walk(c, root)
+	return c.occurrences
+}
+
+type occurrenceCollector struct {
+	document    *project.Document
+	occurrences []occurrence
+}
+
+//nolint:ireturn
+func (c *occurrenceCollector) Visit(node ast.Node) ast.Visitor {
+	n, ok := node.(*ast.MappingValueNode)
+	if !ok {
+		return c
+	}
+	path := n.GetPath()
+	switch n.Key.GetToken().Value {
+	case "name":
+		switch {
+		case taskNamePath.MatchString(path):
+			c.addDefinition(lint.EntityTask, n.Value)
+		case taskGroupNamePath.MatchString(path):
+			c.addDefinition(lint.EntityTaskGroup, n.Value)
+		case buildVariantNamePath.MatchString(path):
+			c.addDefinition(lint.EntityBuildVariant, n.Value)
+		}
+
+	case "tags":
+		kind := entityTag
+		switch {
+		case taskTagsPath.MatchString(path):
+		case buildVariantTagsPath.MatchString(path):
+			kind = entityBuildVariantTag
+		default:
+			return c
+		}
+		for _, entry := range lint.SequenceEntries(n.Value) {
+			c.addDefinition(kind, entry)
+		}
+
+	case "tasks":
+		switch {
+		case buildVariantTasksPath.MatchString(path):
+			for _, entry := range lint.SequenceEntries(n.Value) {
+				c.addSelector(lint.EntityTask, entityTag, lint.EntryField(entry, "name"))
+			}
+		case taskGroupTasksPath.MatchString(path):
+			for _, entry := range lint.SequenceEntries(n.Value) {
+				c.addSelector(lint.EntityTask, entityTag, entry)
+			}
+		}
+
+	case "depends_on":
+		for _, entry := range lint.SequenceEntries(n.Value) {
+			c.addSelector(lint.EntityTask, entityTag, lint.EntryField(entry, "name"))
+			c.addSelector(lint.EntityBuildVariant, entityBuildVariantTag, lint.MappingField(entry, "variant"))
+		}
+
+	case "execution_tasks":
+		for _, entry := range lint.SequenceEntries(n.Value) {
+			c.addSelector(lint.EntityTask, entityTag, entry)
+		}
+	}
+	return c
+}
+
+func (c *occurrenceCollector) addDefinition(kind lint.EntityKind, node ast.Node) {
+	if s, ok := lint.UnwrapAnchor(node).(*ast.StringNode); ok {
+		c.addName(symbol{Kind: kind, Name: s.Value}, s, true)
+	}
+}
+
+// addName adds a node whose whole value is the name of a symbol
+func (c *occurrenceCollector) addName(sym symbol, node ast.Node, definition bool) {
+	start, ok := lint.NameStart(node)
+	if !ok {
+		return
+	}
+	c.add(sym, start, definition)
+}
+
+// addSelector adds every name and tag a selector's criteria refer to
+func (c *occurrenceCollector) addSelector(nameKind lint.EntityKind, tagKind lint.EntityKind, node ast.Node) {
+	start, ok := lint.NameStart(node)
+	if !ok {
+		return
+	}
+	value := []rune(node.(*ast.StringNode).Value) //nolint:forcetypeassert // NameStart only accepts strings
+	for i := 0; i < len(value); {
+		if value[i] == ' ' || value[i] == '\t' {
+			i++
+			continue
+		}
+		end := i
+		for end < len(value) && value[end] != ' ' && value[end] != '\t' {
+			end++
+		}
+		criterion := i
+		kind := nameKind
+		if value[criterion] == '!' {
+			criterion++
+		}
+		if criterion < end && value[criterion] == '.' {
+			criterion++
+			kind = tagKind
+		}
+		if name := string(value[criterion:end]); name != "" && name != "*" {
+			at := start
+			at.Character += uint32(criterion) //nolint:gosec
+			c.add(symbol{Kind: kind, Name: name}, at, false)
+		}
+		i = end
+	}
+}
+
+func (c *occurrenceCollector) add(sym symbol, start protocol.Position, definition bool) {
+	end := start
+	end.Character += uint32(len([]rune(sym.Name))) //nolint:gosec
+	c.occurrences = append(c.occurrences, occurrence{
+		Symbol:     sym,
+		Document:   c.document,
+		Range:      protocol.Range{Start: start, End: end},
+		Definition: definition,
+	})
+}
+
+func rangeContains(r protocol.Range, p protocol.Position) bool {
+	before := func(a protocol.Position, b protocol.Position) bool {
+		return a.Line < b.Line || (a.Line == b.Line && a.Character <= b.Character)
+	}
+	return before(r.Start, p) && before(p, r.End)
+}
+
+// displayPath returns a document's path relative to the project root for use
+// in messages
+func displayPath(d *project.Document) string {
+	path := d.URI.Filename()
+	if rel, err := filepath.Rel(d.Workspace.Root(), path); err == nil {
+		return rel
+	}
+	return path
+}
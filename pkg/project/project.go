@@ -3,9 +3,11 @@ package project
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/a-h/templ/lsp/protocol"
@@ -22,6 +24,9 @@ type Project struct {
 	BasePath      string `yaml:"path"`
 	Data          *model.Project
 	TextDocuments map[protocol.DocumentURI]*Document
+	// documentOrder is the order evergreen merges documents in: the main
+	// config first, followed by its includes
+	documentOrder []protocol.DocumentURI
 }
 
 func New(path string) *Project {
@@ -41,8 +46,14 @@ func (w *Project) SetRoot(rootPath string) {
 	w.rootPath = rootPath
 }
 
+// Root returns the directory includes are resolved from
+func (w *Project) Root() string {
+	return w.rootPath
+}
+
 func (w *Project) Init(ctx context.Context) error {
 	w.TextDocuments = make(map[protocol.DocumentURI]*Document)
+	w.documentOrder = nil
 	path := w.Path()
 	cfg, err := os.ReadFile(path)
 	if err != nil {
@@ -80,7 +91,7 @@ func (w *Project) Init(ctx context.Context) error {
 		p := filepath.Join(w.rootPath, i.FileName)
 		docText, err := os.ReadFile(p)
 		if err != nil {
-			slog.Error("Uh oh!")
+			slog.Error("Could not read include", "path", p, "error", err)
 			continue
 		}
 		_, err = w.AddDocument(ctx, protocol.TextDocumentItem{
@@ -90,7 +101,7 @@ func (w *Project) Init(ctx context.Context) error {
 			Text:       string(docText),
 		})
 		if err != nil {
-			slog.Error("Uh oh!")
+			slog.Error("Could not parse include", "path", p, "error", err)
 			continue
 		}
 	}
@@ -109,13 +120,45 @@ func (w *Project) loadProject(ctx context.Context, cfg []byte) error {
 		os.Chdir(w.rootPath)
 		defer os.Chdir(cwd)
 	}
-	_, err := model.LoadProjectInto(ctx, cfg, &model.GetProjectOpts{
+	opts := &model.GetProjectOpts{
 		ReadFileFrom: model.ReadFromLocal,
 		RemotePath:   path,
 		Ref: &model.ProjectRef{
 			RemotePath: path,
 		},
-	}, "id", w.Data)
+	}
+	pp, err := model.LoadProjectInto(ctx, cfg, opts, "id", w.Data)
+	// Evergreen stops at the first include that fails to merge (e.g. a task
+	// declared twice) and leaves the project empty. Keep merging the remaining
+	// includes into what it managed so far so the rest of the project can still
+	// be linted and the duplicates reported where they are declared.
+	for err != nil && pp != nil && strings.Contains(err.Error(), model.MergeProjectConfigError) {
+		slog.Debug("Project loaded with merge errors", "project", path, "error", err)
+		failed := -1
+		for i, include := range pp.Include {
+			if strings.Contains(err.Error(), fmt.Sprintf("merging file '%s'", include.FileName)) {
+				failed = i
+				break
+			}
+		}
+		if failed == -1 {
+			break
+		}
+		pp.Include = pp.Include[failed+1:]
+		merged, marshalErr := yaml.Marshal(pp)
+		if marshalErr != nil {
+			return marshalErr
+		}
+		w.Data = &model.Project{}
+		pp, err = model.LoadProjectInto(ctx, merged, opts, "id", w.Data)
+	}
+	// Evergreen still populates the project when only translation fails (e.g.
+	// a reference to an undefined task), so keep it and let the linters report
+	// those problems at the offending node instead
+	if err != nil && strings.Contains(err.Error(), model.TranslateProjectError) {
+		slog.Debug("Project loaded with translation errors", "project", path, "error", err)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -124,6 +167,9 @@ func (w *Project) loadProject(ctx context.Context, cfg []byte) error {
 }
 
 func (w *Project) AddDocument(ctx context.Context, doc protocol.TextDocumentItem) (*Document, error) {
+	if _, ok := w.TextDocuments[doc.URI]; !ok {
+		w.documentOrder = append(w.documentOrder, doc.URI)
+	}
 	d := &Document{TextDocumentItem: doc, Workspace: w}
 	w.TextDocuments[doc.URI] = d
 	err := d.Parse()
@@ -132,6 +178,18 @@ func (w *Project) AddDocument(ctx context.Context, doc protocol.TextDocumentItem
 
 func (w *Project) RemoveDocument(ctx context.Context, docID protocol.TextDocumentIdentifier) {
 	delete(w.TextDocuments, docID.URI)
+	w.documentOrder = slices.DeleteFunc(w.documentOrder, func(u protocol.DocumentURI) bool {
+		return u == docID.URI
+	})
+}
+
+// Documents returns the project's documents in the order evergreen merges them
+func (w *Project) Documents() []*Document {
+	docs := make([]*Document, 0, len(w.documentOrder))
+	for _, u := range w.documentOrder {
+		docs = append(docs, w.TextDocuments[u])
+	}
+	return docs
 }
 
 func (w *Project) UpdateDocument(ctx context.Context, docID protocol.VersionedTextDocumentIdentifier, textChanges protocol.TextDocumentContentChangeEvent) (*Document, error) {
@@ -197,11 +255,19 @@ type Document struct {
 	Hovers      map[string]DocumentNodeLocation
 	AST         *ast.File
 	Workspace   *Project
+	// ParseError is set when the text is not valid YAML. The AST of the last
+	// valid text, if any, is kept.
+	ParseError error
 }
 
 var deprecatedCommands = []string{"shell.exec"}
 
+// RootNode returns the body of the first YAML document, or nil when the
+// document could never be parsed
 func (d *Document) RootNode() ast.Node {
+	if d.AST == nil || len(d.AST.Docs) == 0 {
+		return nil
+	}
 	return d.AST.Docs[0].Body
 }
 
@@ -215,6 +281,7 @@ func (d *Document) UpdateText(content string, version int32) {
 
 func (d *Document) Parse() error {
 	astFile, err := parser.ParseBytes([]byte(d.Text), parser.ParseComments)
+	d.ParseError = err
 	if err != nil {
 		return err
 	}
@@ -231,14 +298,15 @@ func (d *Document) Parse() error {
 //nolint:ireturn
 func (d *Document) Visit(node ast.Node) ast.Visitor {
 	switch n := node.(type) {
-	case *ast.StringNode:
-		nodeStr := n.Token.Value
-		_, ok := d.Workspace.Data.Functions[nodeStr]
-		if ok {
+	case *ast.MappingValueNode:
+		// Only count actual calls so definitions and unrelated strings sharing a
+		// function's name are not treated as references
+		if n.Key.GetToken().Value == "func" {
+			nodeStr := n.Value.GetToken().Value
 			references := d.References[nodeStr]
 			references = append(references, DocumentNodeLocation{
-				Node:     n,
-				Location: d.LocationFromNode(n),
+				Node:     n.Value,
+				Location: d.LocationFromNode(n.Value),
 			})
 			d.References[nodeStr] = references
 		}
@@ -273,6 +341,9 @@ func (d *Document) LocationFromNode(n ast.Node) protocol.Location {
 
 func (d *Document) NodeFromLocation(position protocol.Position) (ast.Node, error) {
 	root := d.RootNode()
+	if root == nil {
+		return nil, yaml.ErrNotFoundNode
+	}
 	visitor := &util.NodePathVisitor{
 		TargetLine:   int(position.Line) + 1,
 		TargetColumn: int(position.Character) + 1,
@@ -0,0 +1,78 @@
+package reporter
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// checkstyleVersion is the version of the Checkstyle format that is written
+const checkstyleVersion = "4.3"
+
+// Checkstyle writes a Checkstyle XML report once all projects are reported,
+// with an element per file holding an error per diagnostic
+type Checkstyle struct {
+	out    io.Writer
+	report checkstyleReport
+}
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+func (c *Checkstyle) Init() {
+	c.report = checkstyleReport{Version: checkstyleVersion}
+}
+
+func (c *Checkstyle) ReportDiagnostics(ctx context.Context, p *project.Project, diagnostics lint.ExecutorDiagnostics) {
+	for _, doc := range diagnostics.Documents() {
+		file := checkstyleFile{Name: relativePath(p, doc.URI.Filename())}
+		for _, d := range diagnostics[doc] {
+			file.Errors = append(file.Errors, checkstyleError{
+				Line:     oneBased(d.Range.Start.Line),
+				Column:   oneBased(d.Range.Start.Character),
+				Severity: checkstyleSeverity(d.Severity),
+				Message:  d.Message,
+				Source:   d.Source,
+			})
+		}
+		if len(file.Errors) > 0 {
+			c.report.Files = append(c.report.Files, file)
+		}
+	}
+}
+
+func (c *Checkstyle) ReportSummary(ctx context.Context) error {
+	return writeXML(c.out, c.report)
+}
+
+// checkstyleSeverity maps a severity to one of Checkstyle's, which has no
+// hints
+func checkstyleSeverity(s protocol.DiagnosticSeverity) string {
+	switch s {
+	case protocol.DiagnosticSeverityError:
+		return "error"
+	case protocol.DiagnosticSeverityWarning:
+		return "warning"
+	}
+	return "info"
+}
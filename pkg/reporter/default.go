@@ -3,15 +3,20 @@ package reporter
 import (
 	"context"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/a-h/templ/lsp/protocol"
 	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
 )
 
 type Default struct {
+	out                 io.Writer
 	writer              *tabwriter.Writer
 	totalByType         map[string]int
 	totalWarnings       int
@@ -24,17 +29,21 @@ func (d *Default) Init() {
 	d.totalErrors = 0
 	d.totalWarnings = 0
 	d.totalDiagnosticSets = 0
-	d.writer = tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	if d.out == nil {
+		d.out = os.Stdout
+	}
+	d.writer = tabwriter.NewWriter(d.out, 0, 8, 1, '\t', 0)
 }
 
-func (d *Default) ReportDiagnostics(ctx context.Context, diagnostics lint.ExecutorDiagnostics) {
+func (d *Default) ReportDiagnostics(ctx context.Context, _ *project.Project, diagnostics lint.ExecutorDiagnostics) {
 	warningsCount := 0
 	errorsCount := 0
-	for f, diags := range diagnostics {
+	for _, f := range diagnostics.Documents() {
+		diags := diagnostics[f]
 		if len(diags) == 0 {
 			continue
 		}
-		fmt.Println(string(f.URI))
+		fmt.Fprintln(d.out, string(f.URI))
 		for _, diag := range diags {
 			d.totalByType[diag.Source]++
 			switch diag.Severity {
@@ -46,22 +55,23 @@ func (d *Default) ReportDiagnostics(ctx context.Context, diagnostics lint.Execut
 			fmt.Fprintf(d.writer, "\t%d:%d\t%s\t\t%s\t%s\n", diag.Range.Start.Line, diag.Range.Start.Character, strings.ToLower(diag.Severity.String()), diag.Message, diag.Source)
 		}
 		d.writer.Flush()
-		fmt.Println()
+		fmt.Fprintln(d.out)
 	}
-	fmt.Printf("%d problems (%d errors, %d warnings)\n\n", warningsCount+errorsCount, errorsCount, warningsCount)
+	fmt.Fprintf(d.out, "%d problems (%d errors, %d warnings)\n\n", warningsCount+errorsCount, errorsCount, warningsCount)
 	d.totalErrors += errorsCount
 	d.totalWarnings += warningsCount
 	d.totalDiagnosticSets++
 }
 
-func (d *Default) ReportSummary(ctx context.Context) {
-	fmt.Printf("*** Summary ***:\n")
+func (d *Default) ReportSummary(ctx context.Context) error {
+	fmt.Fprintf(d.out, "*** Summary ***:\n")
 	d.writer.Write([]byte("\tRule\tCount\n"))
 	d.writer.Write([]byte("\t----\t-----\n"))
-	for t, c := range d.totalByType {
-		fmt.Fprintf(d.writer, "\t%s\t%d\n", t, c)
+	for _, t := range slices.Sorted(maps.Keys(d.totalByType)) {
+		fmt.Fprintf(d.writer, "\t%s\t%d\n", t, d.totalByType[t])
 	}
 	d.writer.Flush()
-	fmt.Println()
-	fmt.Printf("%d problems across all %d project(s) (%d errors, %d warnings)\n\n", d.totalWarnings+d.totalErrors, d.totalDiagnosticSets, d.totalErrors, d.totalWarnings)
+	fmt.Fprintln(d.out)
+	fmt.Fprintf(d.out, "%d problems across all %d project(s) (%d errors, %d warnings)\n\n", d.totalWarnings+d.totalErrors, d.totalDiagnosticSets, d.totalErrors, d.totalWarnings)
+	return nil
 }
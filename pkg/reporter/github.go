@@ -0,0 +1,61 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// GitHub writes each diagnostic as a GitHub Actions workflow command, which
+// annotates the line in the workflow run and on pull request diffs
+type GitHub struct {
+	out io.Writer
+}
+
+var (
+	// githubData escapes the message of a workflow command
+	githubData = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	// githubProperty escapes the properties of a workflow command, which are
+	// also separated by commas and colons
+	githubProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func (g *GitHub) Init() {}
+
+func (g *GitHub) ReportDiagnostics(ctx context.Context, p *project.Project, diagnostics lint.ExecutorDiagnostics) {
+	for _, doc := range diagnostics.Documents() {
+		file := githubProperty.Replace(relativePath(p, doc.URI.Filename()))
+		for _, d := range diagnostics[doc] {
+			fmt.Fprintf(g.out, "::%s file=%s,line=%d,endLine=%d,col=%d,endColumn=%d,title=%s::%s\n",
+				githubCommand(d.Severity),
+				file,
+				oneBased(d.Range.Start.Line),
+				oneBased(d.Range.End.Line),
+				oneBased(d.Range.Start.Character),
+				oneBased(d.Range.End.Character),
+				githubProperty.Replace(d.Source),
+				githubData.Replace(d.Message),
+			)
+		}
+	}
+}
+
+func (g *GitHub) ReportSummary(ctx context.Context) error {
+	return nil
+}
+
+// githubCommand is the workflow command a severity is annotated with
+func githubCommand(s protocol.DiagnosticSeverity) string {
+	switch s {
+	case protocol.DiagnosticSeverityError:
+		return "error"
+	case protocol.DiagnosticSeverityWarning:
+		return "warning"
+	}
+	return "notice"
+}
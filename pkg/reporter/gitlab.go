@@ -0,0 +1,95 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// GitLab writes every diagnostic as a GitLab Code Quality report once all
+// projects are reported, which GitLab shows on the lines of merge request
+// diffs
+type GitLab struct {
+	out    io.Writer
+	issues []gitlabIssue
+	// seen counts the issues with each fingerprint, which GitLab requires to
+	// be unique
+	seen map[string]int
+}
+
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path      string          `json:"path"`
+	Positions gitlabPositions `json:"positions"`
+}
+
+type gitlabPositions struct {
+	Begin gitlabPosition `json:"begin"`
+	End   gitlabPosition `json:"end"`
+}
+
+type gitlabPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (g *GitLab) Init() {
+	g.issues = []gitlabIssue{}
+	g.seen = make(map[string]int)
+}
+
+func (g *GitLab) ReportDiagnostics(ctx context.Context, p *project.Project, diagnostics lint.ExecutorDiagnostics) {
+	for _, doc := range diagnostics.Documents() {
+		path := relativePath(p, doc.URI.Filename())
+		for _, d := range diagnostics[doc] {
+			fingerprint := lint.Fingerprint(p.Root(), doc, d)
+			g.seen[fingerprint]++
+			if n := g.seen[fingerprint]; n > 1 {
+				fingerprint = fmt.Sprintf("%s-%d", fingerprint, n)
+			}
+			g.issues = append(g.issues, gitlabIssue{
+				Description: d.Message,
+				CheckName:   d.Source,
+				Fingerprint: fingerprint,
+				Severity:    gitlabSeverity(d.Severity),
+				Location: gitlabLocation{
+					Path: path,
+					Positions: gitlabPositions{
+						Begin: gitlabPosition{Line: oneBased(d.Range.Start.Line), Column: oneBased(d.Range.Start.Character)},
+						End:   gitlabPosition{Line: oneBased(d.Range.End.Line), Column: oneBased(d.Range.End.Character)},
+					},
+				},
+			})
+		}
+	}
+}
+
+func (g *GitLab) ReportSummary(ctx context.Context) error {
+	encoder := json.NewEncoder(g.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(g.issues)
+}
+
+// gitlabSeverity maps a severity to one of GitLab's, which are info, minor,
+// major, critical and blocker
+func gitlabSeverity(s protocol.DiagnosticSeverity) string {
+	switch s {
+	case protocol.DiagnosticSeverityError:
+		return "major"
+	case protocol.DiagnosticSeverityWarning:
+		return "minor"
+	}
+	return "info"
+}
@@ -0,0 +1,115 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// jsonVersion is bumped whenever the JSON format changes in a way that is not
+// backwards compatible
+const jsonVersion = 1
+
+// JSON writes every diagnostic as a single JSON document once all projects
+// are reported. Paths are relative to the workspace root and positions are
+// 1-based.
+type JSON struct {
+	out    io.Writer
+	report jsonReport
+}
+
+type jsonReport struct {
+	Version     int              `json:"version"`
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+	Summary     jsonSummary      `json:"summary"`
+}
+
+type jsonSummary struct {
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Infos    int `json:"infos"`
+	Hints    int `json:"hints"`
+}
+
+type jsonDiagnostic struct {
+	Project  string    `json:"project"`
+	File     string    `json:"file"`
+	Range    jsonRange `json:"range"`
+	Severity string    `json:"severity"`
+	Rule     string    `json:"rule"`
+	Message  string    `json:"message"`
+	Fix      *jsonFix  `json:"fix,omitempty"`
+}
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type jsonRange struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+type jsonFix struct {
+	Title string     `json:"title"`
+	Edits []jsonEdit `json:"edits"`
+}
+
+type jsonEdit struct {
+	Range   jsonRange `json:"range"`
+	NewText string    `json:"newText"`
+}
+
+func (j *JSON) Init() {
+	j.report = jsonReport{Version: jsonVersion, Diagnostics: []jsonDiagnostic{}}
+}
+
+func (j *JSON) ReportDiagnostics(ctx context.Context, p *project.Project, diagnostics lint.ExecutorDiagnostics) {
+	for _, doc := range diagnostics.Documents() {
+		for _, d := range diagnostics[doc] {
+			switch d.Severity {
+			case protocol.DiagnosticSeverityError:
+				j.report.Summary.Errors++
+			case protocol.DiagnosticSeverityWarning:
+				j.report.Summary.Warnings++
+			case protocol.DiagnosticSeverityInformation:
+				j.report.Summary.Infos++
+			case protocol.DiagnosticSeverityHint:
+				j.report.Summary.Hints++
+			}
+			diagnostic := jsonDiagnostic{
+				Project:  p.BasePath,
+				File:     relativePath(p, doc.URI.Filename()),
+				Range:    oneBasedRange(d.Range),
+				Severity: severityName(d.Severity),
+				Rule:     d.Source,
+				Message:  d.Message,
+			}
+			if fix, ok := lint.FixOf(d); ok {
+				diagnostic.Fix = &jsonFix{Title: fix.Title, Edits: make([]jsonEdit, 0, len(fix.Edits))}
+				for _, e := range fix.Edits {
+					diagnostic.Fix.Edits = append(diagnostic.Fix.Edits, jsonEdit{Range: oneBasedRange(e.Range), NewText: e.NewText})
+				}
+			}
+			j.report.Diagnostics = append(j.report.Diagnostics, diagnostic)
+		}
+	}
+}
+
+func (j *JSON) ReportSummary(ctx context.Context) error {
+	encoder := json.NewEncoder(j.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(j.report)
+}
+
+func oneBasedRange(r protocol.Range) jsonRange {
+	return jsonRange{
+		Start: jsonPosition{Line: oneBased(r.Start.Line), Column: oneBased(r.Start.Character)},
+		End:   jsonPosition{Line: oneBased(r.End.Line), Column: oneBased(r.End.Character)},
+	}
+}
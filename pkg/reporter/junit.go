@@ -0,0 +1,94 @@
+package reporter
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// JUnit writes a JUnit XML report once all projects are reported, with a
+// test suite per project, a test case per document and a failure per
+// diagnostic
+type JUnit struct {
+	out    io.Writer
+	report junitTestSuites
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (j *JUnit) Init() {
+	j.report = junitTestSuites{Name: "evergreen-lsp"}
+}
+
+func (j *JUnit) ReportDiagnostics(ctx context.Context, p *project.Project, diagnostics lint.ExecutorDiagnostics) {
+	suite := junitTestSuite{Name: p.BasePath}
+	// Every document is a test case so documents without diagnostics show up
+	// as passing
+	for _, doc := range p.Documents() {
+		path := relativePath(p, doc.URI.Filename())
+		testCase := junitTestCase{Name: path, ClassName: p.BasePath}
+		for _, d := range diagnostics[doc] {
+			testCase.Failures = append(testCase.Failures, junitFailure{
+				Message: d.Message,
+				Type:    d.Source,
+				Text: fmt.Sprintf("%s:%d:%d: %s: %s (%s)",
+					path, oneBased(d.Range.Start.Line), oneBased(d.Range.Start.Character), severityName(d.Severity), d.Message, d.Source),
+			})
+		}
+		suite.Tests++
+		if len(testCase.Failures) > 0 {
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	j.report.Tests += suite.Tests
+	j.report.Failures += suite.Failures
+	j.report.Suites = append(j.report.Suites, suite)
+}
+
+func (j *JUnit) ReportSummary(ctx context.Context) error {
+	return writeXML(j.out, j.report)
+}
+
+// writeXML writes an indented XML document with a header
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
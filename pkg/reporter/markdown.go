@@ -0,0 +1,113 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// markdownCell escapes text so it stays inside a table cell and is not read
+// as HTML
+var markdownCell = strings.NewReplacer("|", "\\|", "<", "&lt;", ">", "&gt;", "\r", "", "\n", "<br>")
+
+// Markdown writes a summary of every diagnostic once all projects are
+// reported, meant to be posted as a pull request comment. Each file gets a
+// collapsible table of its diagnostics, linked with the link template when
+// one is set.
+type Markdown struct {
+	out          io.Writer
+	linkTemplate string
+	files        map[string][]protocol.Diagnostic
+	rules        map[string]int
+	errors       int
+	warnings     int
+	infos        int
+}
+
+func (m *Markdown) Init() {
+	m.files = make(map[string][]protocol.Diagnostic)
+	m.rules = make(map[string]int)
+}
+
+func (m *Markdown) ReportDiagnostics(ctx context.Context, p *project.Project, diagnostics lint.ExecutorDiagnostics) {
+	for _, doc := range diagnostics.Documents() {
+		path := relativePath(p, doc.URI.Filename())
+		for _, d := range diagnostics[doc] {
+			switch d.Severity {
+			case protocol.DiagnosticSeverityError:
+				m.errors++
+			case protocol.DiagnosticSeverityWarning:
+				m.warnings++
+			default:
+				m.infos++
+			}
+			m.rules[d.Source]++
+			// Projects can share includes, so files are keyed by path
+			m.files[path] = append(m.files[path], d)
+		}
+	}
+}
+
+func (m *Markdown) ReportSummary(ctx context.Context) error {
+	var b strings.Builder
+	b.WriteString("## evergreen-lsp\n\n")
+	total := m.errors + m.warnings + m.infos
+	if total == 0 {
+		b.WriteString("No problems found.\n")
+		_, err := io.WriteString(m.out, b.String())
+		return err
+	}
+	fmt.Fprintf(&b, "**%s** (%s, %s, %s) in %s\n\n", plural(total, "problem"), plural(m.errors, "error"),
+		plural(m.warnings, "warning"), plural(m.infos, "info"), plural(len(m.files), "file"))
+
+	b.WriteString("| Rule | Count |\n| --- | ---: |\n")
+	for _, rule := range slices.Sorted(maps.Keys(m.rules)) {
+		fmt.Fprintf(&b, "| `%s` | %d |\n", rule, m.rules[rule])
+	}
+	b.WriteString("\n")
+
+	for _, path := range slices.Sorted(maps.Keys(m.files)) {
+		diags := slices.Clone(m.files[path])
+		lint.SortDiagnostics(diags)
+		fmt.Fprintf(&b, "<details>\n<summary><code>%s</code> (%s)</summary>\n\n", markdownCell.Replace(path), plural(len(diags), "problem"))
+		b.WriteString("| Line | Severity | Rule | Message |\n| ---: | --- | --- | --- |\n")
+		for _, d := range diags {
+			fmt.Fprintf(&b, "| %s | %s | `%s` | %s |\n", m.position(path, d), severityName(d.Severity), d.Source, markdownCell.Replace(d.Message))
+		}
+		b.WriteString("\n</details>\n\n")
+	}
+	_, err := io.WriteString(m.out, b.String())
+	return err
+}
+
+// position returns the 1-based position of a diagnostic, linked when there is
+// a link template
+func (m *Markdown) position(path string, d protocol.Diagnostic) string {
+	line := strconv.Itoa(oneBased(d.Range.Start.Line))
+	position := line + ":" + strconv.Itoa(oneBased(d.Range.Start.Character))
+	if m.linkTemplate == "" {
+		return position
+	}
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	link := strings.NewReplacer("{file}", strings.Join(segments, "/"), "{line}", line).Replace(m.linkTemplate)
+	return "[" + position + "](" + link + ")"
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
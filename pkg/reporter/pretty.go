@@ -0,0 +1,202 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+// prettyMaxLines is the most source lines shown for a single diagnostic
+const prettyMaxLines = 5
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// Pretty prints every diagnostic with the source lines it covers, underlined,
+// followed by its fix if it has one. Output is coloured when written to a
+// terminal.
+type Pretty struct {
+	out      io.Writer
+	color    bool
+	errors   int
+	warnings int
+	infos    int
+}
+
+func (p *Pretty) Init() {
+	p.color = isTerminal(p.out)
+}
+
+func (p *Pretty) ReportDiagnostics(ctx context.Context, proj *project.Project, diagnostics lint.ExecutorDiagnostics) {
+	for _, doc := range diagnostics.Documents() {
+		path := relativePath(proj, doc.URI.Filename())
+		lines := strings.Split(doc.Text, "\n")
+		for _, d := range diagnostics[doc] {
+			switch d.Severity {
+			case protocol.DiagnosticSeverityError:
+				p.errors++
+			case protocol.DiagnosticSeverityWarning:
+				p.warnings++
+			default:
+				p.infos++
+			}
+			p.printDiagnostic(path, lines, d)
+		}
+	}
+}
+
+func (p *Pretty) printDiagnostic(path string, lines []string, d protocol.Diagnostic) {
+	severityColor := prettySeverityColor(d.Severity)
+	fmt.Fprintf(p.out, "%s: %s %s\n",
+		p.paint(ansiBold+severityColor, severityName(d.Severity)),
+		p.paint(ansiBold, d.Message),
+		p.paint(ansiCyan, "("+d.Source+")"))
+
+	start, end := int(d.Range.Start.Line), int(d.Range.End.Line)
+	// A range ending at the start of a line does not cover that line
+	if end > start && d.Range.End.Character == 0 {
+		end--
+	}
+	last := min(end, start+prettyMaxLines-1, len(lines)-1)
+	width := len(strconv.Itoa(last + 1))
+	gutter := strings.Repeat(" ", width)
+
+	fmt.Fprintf(p.out, "%s %s %s:%d:%d\n", gutter, p.paint(ansiBlue, "-->"), path, oneBased(d.Range.Start.Line), oneBased(d.Range.Start.Character))
+	fmt.Fprintf(p.out, "%s %s\n", gutter, p.paint(ansiBlue, "|"))
+	for i := start; i <= last; i++ {
+		line := []rune(strings.TrimSuffix(lines[i], "\r"))
+		from, to := 0, len(line)
+		if i == start {
+			from = min(int(d.Range.Start.Character), len(line))
+		}
+		if i == int(d.Range.End.Line) {
+			to = min(int(d.Range.End.Character), len(line))
+		}
+		if i != start {
+			// Only underline the content of lines after the first
+			from = min(len(line)-len([]rune(strings.TrimLeft(string(line), " \t"))), to)
+		}
+		fmt.Fprintf(p.out, "%*d %s %s\n", width, i+1, p.paint(ansiBlue, "|"), string(line))
+		fmt.Fprintf(p.out, "%s %s %s%s\n", gutter, p.paint(ansiBlue, "|"), indentation(line[:from]),
+			p.paint(severityColor, strings.Repeat("^", max(to-from, 1))))
+	}
+	if last < end {
+		fmt.Fprintf(p.out, "%s %s ...\n", gutter, p.paint(ansiBlue, "|"))
+	}
+
+	if fix, ok := lint.FixOf(d); ok {
+		fmt.Fprintf(p.out, "%s %s %s\n", gutter, p.paint(ansiBlue, "="), p.paint(ansiGreen, "fix: "+fix.Title))
+		for _, l := range fixedLines(lines, fix) {
+			fmt.Fprintf(p.out, "%*d %s %s\n", width, l.number, p.paint(ansiGreen, "+"), l.text)
+		}
+	}
+	fmt.Fprintln(p.out)
+}
+
+func (p *Pretty) ReportSummary(ctx context.Context) error {
+	total := p.errors + p.warnings + p.infos
+	summary := fmt.Sprintf("%d problems (%d errors, %d warnings, %d infos)", total, p.errors, p.warnings, p.infos)
+	switch {
+	case p.errors > 0:
+		summary = p.paint(ansiBold+ansiRed, summary)
+	case p.warnings > 0:
+		summary = p.paint(ansiBold+ansiYellow, summary)
+	}
+	_, err := fmt.Fprintln(p.out, summary)
+	return err
+}
+
+// paint wraps text in an ANSI style when colour is enabled
+func (p *Pretty) paint(style string, text string) string {
+	if !p.color {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+func prettySeverityColor(s protocol.DiagnosticSeverity) string {
+	switch s {
+	case protocol.DiagnosticSeverityError:
+		return ansiRed
+	case protocol.DiagnosticSeverityWarning:
+		return ansiYellow
+	}
+	return ansiCyan
+}
+
+// indentation returns whitespace as wide as the given text, keeping its tabs
+// so an underline lines up with the source line above it
+func indentation(text []rune) string {
+	var b strings.Builder
+	for _, r := range text {
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
+
+type fixedLine struct {
+	number int
+	text   string
+}
+
+// fixedLines returns the lines a fix changes as they are after applying it.
+// Nothing is returned for fixes that add or remove lines, for which the title
+// has to do.
+func fixedLines(lines []string, fix *lint.Fix) []fixedLine {
+	byLine := make(map[int][]protocol.TextEdit)
+	for _, e := range fix.Edits {
+		if e.Range.Start.Line != e.Range.End.Line || strings.Contains(e.NewText, "\n") || int(e.Range.Start.Line) >= len(lines) {
+			return nil
+		}
+		byLine[int(e.Range.Start.Line)] = append(byLine[int(e.Range.Start.Line)], e)
+	}
+	fixed := make([]fixedLine, 0, len(byLine))
+	for line, edits := range byLine {
+		// Apply from the end of the line so earlier characters stay valid
+		slices.SortFunc(edits, func(a protocol.TextEdit, b protocol.TextEdit) int {
+			return int(b.Range.Start.Character) - int(a.Range.Start.Character)
+		})
+		text := []rune(strings.TrimSuffix(lines[line], "\r"))
+		for _, e := range edits {
+			from := min(int(e.Range.Start.Character), len(text))
+			to := min(int(e.Range.End.Character), len(text))
+			text = slices.Concat(text[:from], []rune(e.NewText), text[to:])
+		}
+		fixed = append(fixed, fixedLine{number: line + 1, text: string(text)})
+	}
+	slices.SortFunc(fixed, func(a fixedLine, b fixedLine) int { return a.number - b.number })
+	return fixed
+}
+
+// isTerminal reports whether w is a terminal, unless colour is turned off
+// with NO_COLOR
+func isTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
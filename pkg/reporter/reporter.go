@@ -2,15 +2,71 @@ package reporter
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
+	"path/filepath"
+	"strings"
 
 	"github.com/a-h/templ/lsp/protocol"
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
 )
 
 type Reporter interface {
 	Init()
-	ReportDiagnostics(ctx context.Context, diagnostics []protocol.Diagnostic)
-	ReportSummary(ctx context.Context)
+	// ReportDiagnostics is called once per project
+	ReportDiagnostics(ctx context.Context, project *project.Project, diagnostics lint.ExecutorDiagnostics)
+	// ReportSummary is called once every project is reported
+	ReportSummary(ctx context.Context) error
+}
+
+// Options configure the reporters that support them
+type Options struct {
+	// LinkTemplate is a URL with {file} and {line} placeholders that reports
+	// link each diagnostic with
+	LinkTemplate string
+}
+
+// Format is a reporter that can be selected by name
+type Format struct {
+	Name string
+	New  func(w io.Writer, opts Options) Reporter
+}
+
+var Formats = []Format{
+	{Name: "default", New: func(w io.Writer, _ Options) Reporter { return &Default{out: w} }},
+	{Name: "pretty", New: func(w io.Writer, _ Options) Reporter { return &Pretty{out: w} }},
+	{Name: "json", New: func(w io.Writer, _ Options) Reporter { return &JSON{out: w} }},
+	{Name: "sarif", New: func(w io.Writer, _ Options) Reporter { return &SARIF{out: w} }},
+	{Name: "github", New: func(w io.Writer, _ Options) Reporter { return &GitHub{out: w} }},
+	{Name: "gitlab", New: func(w io.Writer, _ Options) Reporter { return &GitLab{out: w} }},
+	{Name: "junit", New: func(w io.Writer, _ Options) Reporter { return &JUnit{out: w} }},
+	{Name: "checkstyle", New: func(w io.Writer, _ Options) Reporter { return &Checkstyle{out: w} }},
+	{Name: "markdown", New: func(w io.Writer, opts Options) Reporter { return &Markdown{out: w, linkTemplate: opts.LinkTemplate} }},
+}
+
+// FormatNames returns the names of every format
+func FormatNames() []string {
+	names := make([]string, 0, len(Formats))
+	for _, f := range Formats {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// New returns an initialized reporter of the given format writing to w
+//
+//nolint:ireturn
+func New(format string, w io.Writer, opts Options) (Reporter, error) {
+	for _, f := range Formats {
+		if f.Name == format {
+			r := f.New(w, opts)
+			r.Init()
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown format %q, must be one of %s", format, strings.Join(FormatNames(), ", "))
 }
 
 func diagnosticSeverityToLogLevel(s protocol.DiagnosticSeverity) slog.Level {
@@ -26,3 +82,34 @@ func diagnosticSeverityToLogLevel(s protocol.DiagnosticSeverity) slog.Level {
 	}
 	return slog.LevelInfo
 }
+
+// severityName is the name a severity is configured with
+func severityName(s protocol.DiagnosticSeverity) string {
+	switch s {
+	case protocol.DiagnosticSeverityError:
+		return "error"
+	case protocol.DiagnosticSeverityWarning:
+		return "warning"
+	case protocol.DiagnosticSeverityInformation:
+		return "info"
+	case protocol.DiagnosticSeverityHint:
+		return "hint"
+	}
+	return "info"
+}
+
+// oneBased converts a 0-based line or character to the 1-based numbering
+// reports use. It converts to int first, since adding to the largest uint32
+// would wrap around to 0.
+func oneBased(n uint32) int {
+	return int(n) + 1
+}
+
+// relativePath returns a path relative to the project root with forward
+// slashes, so reports are the same on every machine
+func relativePath(p *project.Project, path string) string {
+	if rel, err := filepath.Rel(p.Root(), path); err == nil {
+		path = rel
+	}
+	return filepath.ToSlash(path)
+}
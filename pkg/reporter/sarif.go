@@ -0,0 +1,228 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/a-h/templ/lsp/protocol"
+	"github.com/a-h/templ/lsp/uri"
+	"github.com/lavigneer/evergreen-lsp/pkg/lint"
+	"github.com/lavigneer/evergreen-lsp/pkg/project"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// sarifRoot is the base the locations of results are relative to
+	sarifRoot = "%SRCROOT%"
+	// sarifFingerprint is the key of the fingerprint in partialFingerprints,
+	// versioned in case the way it is computed changes
+	sarifFingerprint = "evergreenLspPath/v1"
+)
+
+// SARIF writes a SARIF 2.1.0 log with a single run once all projects are
+// reported
+type SARIF struct {
+	out       io.Writer
+	run       sarifRun
+	ruleIndex map[string]int
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	Help                 *sarifMessage      `json:"help,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	RelatedLocations    []sarifLocation   `json:"relatedLocations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Fixes               []sarifFix        `json:"fixes,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion  `json:"deletedRegion"`
+	InsertedContent sarifMessage `json:"insertedContent"`
+}
+
+func (s *SARIF) Init() {
+	s.run = sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "evergreen-lsp",
+			InformationURI: "https://github.com/lavigneer/evergreen-lsp",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	s.ruleIndex = make(map[string]int)
+	for _, r := range lint.Rules {
+		s.addRule(sarifRule{
+			ID:                   r.ID,
+			ShortDescription:     sarifMessage{Text: r.Description},
+			Help:                 &sarifMessage{Text: r.Help},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.Severity)},
+		})
+	}
+}
+
+func (s *SARIF) addRule(rule sarifRule) int {
+	s.ruleIndex[rule.ID] = len(s.run.Tool.Driver.Rules)
+	s.run.Tool.Driver.Rules = append(s.run.Tool.Driver.Rules, rule)
+	return s.ruleIndex[rule.ID]
+}
+
+func (s *SARIF) ReportDiagnostics(ctx context.Context, p *project.Project, diagnostics lint.ExecutorDiagnostics) {
+	if s.run.OriginalURIBaseIDs == nil {
+		s.run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+			sarifRoot: {URI: string(uri.File(p.Root())) + "/"},
+		}
+	}
+	for _, doc := range diagnostics.Documents() {
+		artifact := sarifArtifactLocation{URI: relativePath(p, doc.URI.Filename()), URIBaseID: sarifRoot}
+		for _, d := range diagnostics[doc] {
+			index, ok := s.ruleIndex[d.Source]
+			if !ok {
+				// Custom rules are only known by the diagnostics they report
+				index = s.addRule(sarifRule{
+					ID:                   d.Source,
+					ShortDescription:     sarifMessage{Text: d.Message},
+					DefaultConfiguration: sarifConfiguration{Level: sarifLevel(d.Severity)},
+				})
+			}
+			result := sarifResult{
+				RuleID:    d.Source,
+				RuleIndex: index,
+				Level:     sarifLevel(d.Severity),
+				Message:   sarifMessage{Text: d.Message},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: artifact, Region: sarifRegionOf(d.Range)},
+				}},
+				PartialFingerprints: map[string]string{
+					sarifFingerprint: lint.Fingerprint(p.Root(), doc, d),
+				},
+			}
+			for i, related := range d.RelatedInformation {
+				result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+					ID: &i,
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: relativePath(p, related.Location.URI.Filename()), URIBaseID: sarifRoot},
+						Region:           sarifRegionOf(related.Location.Range),
+					},
+					Message: &sarifMessage{Text: related.Message},
+				})
+			}
+			if fix, ok := lint.FixOf(d); ok {
+				change := sarifArtifactChange{ArtifactLocation: artifact}
+				for _, e := range fix.Edits {
+					change.Replacements = append(change.Replacements, sarifReplacement{
+						DeletedRegion:   sarifRegionOf(e.Range),
+						InsertedContent: sarifMessage{Text: e.NewText},
+					})
+				}
+				result.Fixes = []sarifFix{{Description: sarifMessage{Text: fix.Title}, ArtifactChanges: []sarifArtifactChange{change}}}
+			}
+			s.run.Results = append(s.run.Results, result)
+		}
+	}
+}
+
+func (s *SARIF) ReportSummary(ctx context.Context) error {
+	encoder := json.NewEncoder(s.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{s.run},
+	})
+}
+
+func sarifLevel(s protocol.DiagnosticSeverity) string {
+	switch s {
+	case protocol.DiagnosticSeverityError:
+		return "error"
+	case protocol.DiagnosticSeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+// sarifRegionOf converts a range to a region, which uses 1-based lines and
+// columns
+func sarifRegionOf(r protocol.Range) sarifRegion {
+	return sarifRegion{
+		StartLine:   oneBased(r.Start.Line),
+		StartColumn: oneBased(r.Start.Character),
+		EndLine:     oneBased(r.End.Line),
+		EndColumn:   oneBased(r.End.Character),
+	}
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"context"
+	"strings"
 
 	"github.com/a-h/templ/lsp/protocol"
 	"github.com/goccy/go-yaml"
@@ -37,10 +38,6 @@ func RangeFromNode(n ast.Node, offsetNode ast.Node) protocol.Range {
 		offsetNodeToken := offsetNode.GetToken()
 		line += uint32(offsetNodeToken.Position.Line) + 1
 	}
-	if n.GetComment() != nil {
-		line++
-		// line += uint32(n.GetComment().GetToken().Position.Line)
-	}
 	return protocol.Range{
 		Start: protocol.Position{
 			Line:      line,
@@ -52,3 +49,43 @@ func RangeFromNode(n ast.Node, offsetNode ast.Node) protocol.Range {
 		},
 	}
 }
+
+// ClosestMatch returns the candidate with the smallest edit distance to s, as
+// long as it is close enough to plausibly be a typo
+func ClosestMatch(s string, candidates []string) (string, bool) {
+	best := ""
+	bestDistance := -1
+	for _, c := range candidates {
+		d := editDistance(strings.ToLower(s), strings.ToLower(c))
+		if bestDistance == -1 || d < bestDistance {
+			best = c
+			bestDistance = d
+		}
+	}
+	// Allow roughly one mistake per three characters, which rules out
+	// suggestions for very short names
+	if bestDistance == -1 || bestDistance == 0 || bestDistance > len(s)/3 {
+		return "", false
+	}
+	return best, true
+}
+
+func editDistance(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}